@@ -8,7 +8,7 @@ func main() {
 	cfg := app.DefaultConfig()
 	application, err := app.New(cfg)
 	if err != nil {
-		println("error creating application:", err)
+		println("# error creating application:", err)
 		return
 	}
 
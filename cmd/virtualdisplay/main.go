@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"pico_co2/internal/display"
+	"pico_co2/internal/hal"
 	"pico_co2/internal/types"
 )
 
@@ -24,15 +25,15 @@ func simulateSensor(x uint8) uint16 {
 func main() {
 	vd := display.NewVirtualDisplay(displayWidth, displayHeight)
 
+	clock := hal.NewFakeClock(time.Date(2025, 12, 21, 6, 23, 0, 0, time.UTC))
 	testReadings := types.InitReadings(queueCapacity)
-	testReadings.Time.LastRead = time.Now()
+	testReadings.SetClock(clock)
 	testReadings.Time.Hour = 14
 	testReadings.Time.Minute = 23
-	testReadings.FirstReadingAt = testReadings.FirstReadingAt.Add(-3 * time.Minute)
+	testReadings.Time.Trusted = true
 
 	countMeasurements := queueCapacity
 	for i := range countMeasurements {
-		testReadings.History.AddedAt = testReadings.FirstReadingAt.Add(-2 * time.Minute)
 		// use formula to generate graph data with increasing and decreasing values
 		co2 := simulateSensor(uint8(i))
 		temperature := 22.5 + float64(i)/10.0
@@ -42,7 +43,9 @@ func main() {
 			float32(temperature),
 			float32(humidity),
 		)
+		clock.Advance(time.Minute)
 	}
+	testReadings.Time.LastRead = clock.Now()
 
 	testCases := []struct {
 		name     string
@@ -0,0 +1,300 @@
+// Package alert evaluates threshold rules over the readings and tracks
+// which alerts are active.
+//
+// A rule enters at its Enter threshold and clears at its Exit threshold, so
+// values hovering around a limit do not toggle the alert. The direction is
+// given by the thresholds: Exit below Enter alerts on high values, Exit
+// above Enter on low values.
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pico_co2/internal/types"
+	"pico_co2/internal/types/status"
+)
+
+type Metric uint8
+
+const (
+	MetricCO2         Metric = iota // ppm
+	MetricCO2Rate                   // ppm per hour, see types.Forecast
+	MetricHeatIndex                 // °C
+	MetricTemperature               // °C
+	MetricHumidity                  // %RH
+	MetricTVOC                      // ppb
+	MetricECO2                      // ppm
+	MetricAQI                       // UBA index, 1-5
+)
+
+var MetricStrings = [...]string{
+	"co2",
+	"co2-rate",
+	"heat-index",
+	"temperature",
+	"humidity",
+	"tvoc",
+	"eco2",
+	"aqi",
+}
+
+func (m Metric) String() string {
+	if m > MetricAQI {
+		return "unknown"
+	}
+	return MetricStrings[m]
+}
+
+// ParseMetric returns the metric with the given String name.
+func ParseMetric(name string) (Metric, bool) {
+	for i, n := range MetricStrings {
+		if n == name {
+			return Metric(i), true
+		}
+	}
+	return 0, false
+}
+
+func (m Metric) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+func (m *Metric) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	v, ok := ParseMetric(name)
+	if !ok {
+		return fmt.Errorf("unknown metric %q", name)
+	}
+	*m = v
+	return nil
+}
+
+// Value returns the current value of m, or false when no sensor provides it.
+func (m Metric) Value(r *types.Readings) (float32, bool) {
+	raw := r.Raw
+	switch m {
+	case MetricCO2:
+		return float32(raw.CO2), raw.Valid.Has(types.CO2)
+	case MetricCO2Rate:
+		return r.Calculated.CO2Slope, r.Calculated.CO2SlopeValid
+	case MetricHeatIndex:
+		return status.HeatIndexVal(raw.Temperature, raw.Humidity), raw.Valid.Has(types.Temperature | types.Humidity)
+	case MetricTemperature:
+		return raw.Temperature, raw.Valid.Has(types.Temperature)
+	case MetricHumidity:
+		return raw.Humidity, raw.Valid.Has(types.Humidity)
+	case MetricTVOC:
+		return float32(raw.TVOC), raw.Valid.Has(types.TVOC)
+	case MetricECO2:
+		return float32(raw.ECO2), raw.Valid.Has(types.ECO2)
+	case MetricAQI:
+		return float32(raw.AQI) + 1, raw.Valid.Has(types.AQI)
+	}
+	return 0, false
+}
+
+// Rule describes when an alert fires.
+type Rule struct {
+	Name     string
+	Metric   Metric
+	Enter    float32       // threshold at which the alert becomes pending
+	Exit     float32       // threshold at which an active alert clears
+	For      time.Duration // time the value must stay past Enter
+	Severity types.Severity
+}
+
+// rising reports whether the rule alerts on high values.
+func (r Rule) rising() bool {
+	return r.Exit <= r.Enter
+}
+
+func (r Rule) entered(v float32) bool {
+	if r.rising() {
+		return v >= r.Enter
+	}
+	return v <= r.Enter
+}
+
+func (r Rule) exited(v float32) bool {
+	if r.rising() {
+		return v < r.Exit
+	}
+	return v > r.Exit
+}
+
+type State uint8
+
+const (
+	Inactive State = iota
+	Pending        // past Enter, waiting for For to pass
+	Active
+)
+
+var StateStrings = [...]string{
+	"inactive",
+	"pending",
+	"active",
+}
+
+func (s State) String() string {
+	if s > Active {
+		return "unknown"
+	}
+	return StateStrings[s]
+}
+
+// Alert is the state of one rule.
+type Alert struct {
+	Rule         Rule
+	State        State
+	Value        float32
+	Since        time.Time // start of the current state
+	SnoozedUntil time.Time
+}
+
+// Snoozed reports whether the alert was acknowledged and should stay quiet.
+func (a *Alert) Snoozed(now time.Time) bool {
+	return now.Before(a.SnoozedUntil)
+}
+
+// Engine evaluates a set of rules.
+type Engine struct {
+	alerts []Alert
+}
+
+func NewEngine(rules []Rule) *Engine {
+	e := &Engine{}
+	e.SetRules(rules)
+	return e
+}
+
+// SetRules replaces the rules. The state of rules whose name did not
+// change is kept.
+func (e *Engine) SetRules(rules []Rule) {
+	alerts := make([]Alert, len(rules))
+	for i, rule := range rules {
+		alerts[i].Rule = rule
+		if old := e.Alert(rule.Name); old != nil && old.Rule == rule {
+			alerts[i] = *old
+		}
+	}
+	e.alerts = alerts
+}
+
+// Alerts returns the state of every rule.
+func (e *Engine) Alerts() []Alert {
+	return e.alerts
+}
+
+// Alert returns the alert of the named rule, or nil.
+func (e *Engine) Alert(name string) *Alert {
+	for i := range e.alerts {
+		if e.alerts[i].Rule.Name == name {
+			return &e.alerts[i]
+		}
+	}
+	return nil
+}
+
+// Evaluate updates every alert from the readings and returns the alerts
+// that became active or cleared. Alerts whose metric is unavailable keep
+// their state.
+func (e *Engine) Evaluate(r *types.Readings, now time.Time) []*Alert {
+	var changed []*Alert
+	for i := range e.alerts {
+		a := &e.alerts[i]
+		v, ok := a.Rule.Metric.Value(r)
+		if !ok {
+			continue
+		}
+		a.Value = v
+
+		switch a.State {
+		case Inactive:
+			if !a.Rule.entered(v) {
+				continue
+			}
+			a.State, a.Since = Pending, now
+			fallthrough
+		case Pending:
+			if !a.Rule.entered(v) {
+				a.State, a.Since = Inactive, now
+			} else if now.Sub(a.Since) >= a.Rule.For {
+				a.State, a.Since = Active, now
+				changed = append(changed, a)
+			}
+		case Active:
+			if a.Rule.exited(v) {
+				a.State, a.Since = Inactive, now
+				a.SnoozedUntil = time.Time{}
+				changed = append(changed, a)
+			}
+		}
+	}
+
+	r.Alerts = e.active(r.Alerts[:0], now)
+	return changed
+}
+
+// Snooze silences the named active alert, or all active alerts when name
+// is empty, until now+d. It returns the number of alerts that were not
+// snoozed before.
+func (e *Engine) Snooze(name string, d time.Duration, now time.Time) int {
+	n := 0
+	for i := range e.alerts {
+		a := &e.alerts[i]
+		if a.State != Active || (name != "" && a.Rule.Name != name) {
+			continue
+		}
+		if !a.Snoozed(now) {
+			n++
+		}
+		a.SnoozedUntil = now.Add(d)
+	}
+	return n
+}
+
+// Update refreshes the active alerts in r, e.g. after a snooze.
+func (e *Engine) Update(r *types.Readings, now time.Time) {
+	r.Alerts = e.active(r.Alerts[:0], now)
+}
+
+// active appends the active alerts to dst, most severe first.
+func (e *Engine) active(dst []types.ActiveAlert, now time.Time) []types.ActiveAlert {
+	for sev := types.SeverityCritical; ; sev-- {
+		for i := range e.alerts {
+			a := &e.alerts[i]
+			if a.State != Active || a.Rule.Severity != sev {
+				continue
+			}
+			dst = append(dst, types.ActiveAlert{
+				Name:     a.Rule.Name,
+				Severity: sev,
+				Value:    a.Value,
+				Since:    a.Since,
+				Snoozed:  a.Snoozed(now),
+			})
+		}
+		if sev == types.SeverityInfo {
+			return dst
+		}
+	}
+}
+
+// DefaultRules are the rules used when none are configured.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "co2-high", Metric: MetricCO2, Enter: 1000, Exit: 900, For: 2 * time.Minute, Severity: types.SeverityWarning},
+		{Name: "co2-critical", Metric: MetricCO2, Enter: 1500, Exit: 1350, For: 2 * time.Minute, Severity: types.SeverityCritical},
+		{Name: "co2-rising", Metric: MetricCO2Rate, Enter: 300, Exit: 100, For: 5 * time.Minute, Severity: types.SeverityInfo},
+		{Name: "heat", Metric: MetricHeatIndex, Enter: 32, Exit: 30, For: 10 * time.Minute, Severity: types.SeverityWarning},
+		{Name: "humidity-low", Metric: MetricHumidity, Enter: 30, Exit: 35, For: 30 * time.Minute, Severity: types.SeverityInfo},
+		{Name: "humidity-high", Metric: MetricHumidity, Enter: 70, Exit: 65, For: 30 * time.Minute, Severity: types.SeverityInfo},
+		{Name: "tvoc-high", Metric: MetricTVOC, Enter: 1000, Exit: 750, For: 5 * time.Minute, Severity: types.SeverityWarning},
+	}
+}
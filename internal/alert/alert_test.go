@@ -0,0 +1,96 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+)
+
+func TestRuleHysteresisAndDuration(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	r := types.InitReadings(60)
+	r.SetClock(clock)
+	e := NewEngine([]Rule{{
+		Name: "co2-high", Metric: MetricCO2, Enter: 1000, Exit: 900,
+		For: 2 * time.Minute, Severity: types.SeverityWarning,
+	}})
+
+	step := func(co2 uint16) []*Alert {
+		r.AddReadings(co2, 22, 45)
+		changed := e.Evaluate(r, clock.Now())
+		clock.Advance(time.Minute)
+		return changed
+	}
+
+	step(1100)
+	step(1100)
+	if a := e.Alert("co2-high"); a.State != Pending {
+		t.Fatalf("Expected pending before the minimum duration, got %v", a.State)
+	}
+	if changed := step(1100); len(changed) != 1 || changed[0].State != Active {
+		t.Fatalf("Expected the alert to become active, got %v", changed)
+	}
+	if top := r.Alert(); top == nil || top.Name != "co2-high" || top.Value != 1100 {
+		t.Errorf("Expected co2-high in readings, got %+v", top)
+	}
+
+	// Between the thresholds the alert stays active.
+	if changed := step(950); len(changed) != 0 {
+		t.Errorf("Expected no change inside the hysteresis band, got %v", changed)
+	}
+	if changed := step(850); len(changed) != 1 || changed[0].State != Inactive {
+		t.Errorf("Expected the alert to clear below the exit threshold, got %v", changed)
+	}
+	if len(r.Alerts) != 0 {
+		t.Errorf("Expected no active alerts, got %v", r.Alerts)
+	}
+}
+
+func TestLowRuleAndSnooze(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	r := types.InitReadings(60)
+	e := NewEngine([]Rule{
+		{Name: "dry", Metric: MetricHumidity, Enter: 30, Exit: 35, Severity: types.SeverityInfo},
+		{Name: "hot", Metric: MetricTemperature, Enter: 30, Exit: 28, Severity: types.SeverityCritical},
+	})
+
+	r.AddReadings(800, 31, 25)
+	e.Evaluate(r, now)
+	if len(r.Alerts) != 2 || r.Alerts[0].Name != "hot" {
+		t.Fatalf("Expected hot before dry, got %+v", r.Alerts)
+	}
+
+	if n := e.Snooze("", time.Hour, now); n != 2 {
+		t.Errorf("Expected two alerts snoozed, got %d", n)
+	}
+	if n := e.Snooze("", time.Hour, now); n != 0 {
+		t.Errorf("Expected nothing new to snooze, got %d", n)
+	}
+	e.Update(r, now)
+	if r.Alert() != nil {
+		t.Errorf("Expected snoozed alerts to be quiet, got %+v", r.Alert())
+	}
+
+	e.Update(r, now.Add(time.Hour))
+	if top := r.Alert(); top == nil || top.Name != "hot" {
+		t.Errorf("Expected hot to sound again after the snooze, got %+v", top)
+	}
+}
+
+func TestCO2Rate(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	r := types.InitReadings(60)
+	r.SetClock(clock)
+
+	for i := range 6 {
+		r.AddReadings(uint16(600+10*i), 22, 45)
+		clock.Advance(time.Minute)
+	}
+
+	// 10 ppm per minute.
+	if v, ok := MetricCO2Rate.Value(r); !ok || v != 600 {
+		t.Errorf("Expected 600 ppm/h, got %v (%v)", v, ok)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/events"
+	"pico_co2/internal/types"
+)
+
+// subscribeAlerts evaluates the alert rules after every reading.
+func (a *App) subscribeAlerts() {
+	a.events.Subscribe(func(e events.Event) {
+		r := e.Readings
+		changed := a.alerts.Evaluate(r, e.Time)
+		for _, al := range changed {
+			a.publish(events.Event{Kind: events.AlertChanged, Readings: r, Alert: al})
+		}
+		if len(changed) > 0 {
+			r.IsDrawen = false
+			a.scheduleAlertOutputs(e.Time)
+		}
+	}, events.ReadingAdded)
+}
+
+// snoozeAlerts silences the named active alert, or all when name is empty,
+// for Alerts.Snooze. It returns the number of alerts that were sounding.
+func (a *App) snoozeAlerts(readings *types.Readings, name string) int {
+	now := a.clock.Now()
+	n := a.alerts.Snooze(name, a.config.Alerts.Snooze, now)
+	if n > 0 {
+		logln("alerts snoozed for", a.config.Alerts.Snooze.String())
+		a.alerts.Update(readings, now)
+		a.scheduleAlertOutputs(a.updateAlertOutputs(readings))
+		readings.IsDrawen = false
+	}
+	return n
+}
+
+// alertPattern returns the LED and buzzer levels for an alert of the given
+// severity that has been active for elapsed, and the elapsed time at which
+// they change next, 0 if they stay.
+//
+//	info      LED on, silent
+//	warning   LED on, three beeps when the alert starts
+//	critical  LED blinks, a beep every two seconds
+func alertPattern(sev types.Severity, elapsed time.Duration) (led, buzzer bool, next time.Duration) {
+	const beep = 200 * time.Millisecond
+
+	// after returns the start of the next period p.
+	after := func(p time.Duration) time.Duration {
+		return elapsed - elapsed%p + p
+	}
+
+	switch sev {
+	case types.SeverityWarning:
+		if elapsed >= 6*beep {
+			return true, false, 0
+		}
+		return true, elapsed%(2*beep) < beep, after(beep)
+	case types.SeverityCritical:
+		cycle := elapsed % (2 * time.Second)
+		beepEnd := elapsed - cycle + beep
+		if cycle >= beep {
+			beepEnd = after(2 * time.Second)
+		}
+		return elapsed%time.Second < 500*time.Millisecond, cycle < beep,
+			min(after(500*time.Millisecond), beepEnd)
+	}
+	return true, false, 0
+}
+
+// updateAlertOutputs drives the LED and buzzer from the most severe alert
+// that is not snoozed. It returns when the outputs change next: the next
+// step of the pattern or the end of a snooze, zero if neither is due.
+func (a *App) updateAlertOutputs(readings *types.Readings) time.Time {
+	// Pick up expired snoozes between readings.
+	now := a.clock.Now()
+	a.alerts.Update(readings, now)
+
+	var (
+		led, buzzer bool
+		next        time.Time
+	)
+	if top := readings.Alert(); top != nil {
+		var after time.Duration
+		led, buzzer, after = alertPattern(top.Severity, now.Sub(top.Since))
+		if after > 0 {
+			next = top.Since.Add(after)
+		}
+	}
+	for _, al := range a.alerts.Alerts() {
+		if al.State == alert.Active && al.Snoozed(now) &&
+			(next.IsZero() || al.SnoozedUntil.Before(next)) {
+			next = al.SnoozedUntil
+		}
+	}
+
+	if a.led != nil {
+		a.led.Set(led)
+	}
+	if a.buzzer != nil {
+		a.buzzer.Set(buzzer)
+	}
+	return next
+}
+
+// scheduleAlertOutputs runs the alert outputs task at the given time. It
+// only runs while an alert pattern or a snooze is in progress.
+func (a *App) scheduleAlertOutputs(at time.Time) {
+	if a.alertTask != nil {
+		a.alertTask.TriggerAt(at)
+	}
+}
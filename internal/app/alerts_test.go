@@ -0,0 +1,102 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+)
+
+func TestAlertsDriveOutputsAndSnooze(t *testing.T) {
+	a, board := newTestApp(t)
+	led, buzzer := &hal.FakeOutput{}, &hal.FakeOutput{}
+	a.led, a.buzzer = led, buzzer
+	a.alerts.SetRules([]alert.Rule{{
+		Name: "co2", Metric: alert.MetricCO2, Enter: 700, Exit: 600,
+		Severity: types.SeverityCritical,
+	}})
+	readings := a.newReadings()
+
+	a.readSensors(readings)
+	a.updateAlertOutputs(readings)
+	if !led.Level() || !buzzer.Level() {
+		t.Fatalf("Expected LED and buzzer on for a new critical alert")
+	}
+	if readings.IsDrawen {
+		t.Error("Expected a redraw after the alert became active")
+	}
+
+	// The first press silences the alert instead of switching screens.
+	board.Button2.(*hal.FakePin).Press()
+	a.handleInput(readings)
+	if a.displayManager.CurrentDisplay() != 0 {
+		t.Errorf("Expected the screen to stay, got %d", a.displayManager.CurrentDisplay())
+	}
+	if led.Level() || buzzer.Level() {
+		t.Error("Expected outputs off after snooze")
+	}
+
+	board.Clock.Sleep(a.config.Alerts.Snooze)
+	a.updateAlertOutputs(readings)
+	if readings.Alert() == nil || !led.Level() {
+		t.Error("Expected the alert to sound again after the snooze")
+	}
+}
+
+func TestAlertOutputsRunOnlyWhileSounding(t *testing.T) {
+	a, board := newTestApp(t)
+	a.led, a.buzzer = &hal.FakeOutput{}, &hal.FakeOutput{}
+	a.alerts.SetRules(nil)
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+
+	run := func(d time.Duration) (wakeups int) {
+		end := board.Clock.Now().Add(d)
+		for board.Clock.Now().Before(end) {
+			board.Clock.Sleep(s.RunPending())
+			wakeups++
+		}
+		return wakeups
+	}
+
+	// 20 input polls a second and nothing else.
+	if n := run(10 * time.Second); n > 201 {
+		t.Errorf("Expected at most 201 wakeups in 10s, got %d", n)
+	}
+	if a.alertTask.Runs != 0 {
+		t.Errorf("Expected no alert output updates without alerts, got %d", a.alertTask.Runs)
+	}
+
+	a.alerts.SetRules([]alert.Rule{{
+		Name: "co2", Metric: alert.MetricCO2, Enter: 700, Exit: 600,
+		Severity: types.SeverityCritical,
+	}})
+	a.readSensors(readings)
+	run(time.Second)
+	// LED edges every 500 ms, buzzer on and off once.
+	if runs := a.alertTask.Runs; runs < 3 || runs > 5 {
+		t.Errorf("Expected the alert outputs to follow the pattern, got %d runs", runs)
+	}
+}
+
+func TestAlertPatternNext(t *testing.T) {
+	tests := []struct {
+		sev     types.Severity
+		elapsed time.Duration
+		next    time.Duration
+	}{
+		{types.SeverityCritical, 0, 200 * time.Millisecond},
+		{types.SeverityCritical, 300 * time.Millisecond, 500 * time.Millisecond},
+		{types.SeverityCritical, 1900 * time.Millisecond, 2 * time.Second},
+		{types.SeverityWarning, 1100 * time.Millisecond, 1200 * time.Millisecond},
+		{types.SeverityWarning, 1200 * time.Millisecond, 0},
+		{types.SeverityInfo, 0, 0},
+	}
+	for _, tt := range tests {
+		if _, _, next := alertPattern(tt.sev, tt.elapsed); next != tt.next {
+			t.Errorf("%s after %v: expected the next change at %v, got %v", tt.sev, tt.elapsed, tt.next, next)
+		}
+	}
+}
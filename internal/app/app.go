@@ -1,181 +1,347 @@
 package app
 
 import (
-	"cmp"
+	"errors"
 	"fmt"
-	"machine"
+	"io"
+	"os"
+	"pico_co2/internal/alert"
 	"pico_co2/internal/button"
 	"pico_co2/internal/display"
+	"pico_co2/internal/events"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/menu"
+	"pico_co2/internal/sensor"
+	"pico_co2/internal/shell"
+	"pico_co2/internal/store"
+	"pico_co2/internal/telemetry"
 	"pico_co2/internal/types"
-	"pico_co2/pkg/ens160"
+	"pico_co2/pkg/scheduler"
+	"pico_co2/pkg/tz"
 	"time"
 
 	"tinygo.org/x/drivers"
-	"tinygo.org/x/drivers/aht20"
 	"tinygo.org/x/drivers/ds3231"
-	"tinygo.org/x/drivers/scd4x"
-	"tinygo.org/x/drivers/ssd1306"
 )
 
 type Config struct {
 	Display struct {
-		Width   int16
-		Height  int16
-		Address uint16
+		Width      int16
+		Height     int16
+		Address    uint16
+		Brightness uint8 // 0-255, outside the night periods
 	}
+	// Night dims the display and turns it off at local times of day. A
+	// button press brightens the display for Wake: from off to Brightness
+	// and from Dim to Display.Brightness.
+	Night struct {
+		Brightness uint8 // 0-255, during Dim and while woken from Off
+		Dim        Period
+		Off        Period
+		Wake       time.Duration
+	}
+	BurnIn display.BurnInConfig
+	// Carousel cycles through Screens on its own. It pauses for Pause
+	// after a manual screen change and shows AlertScreen while an alert
+	// that is not snoozed is active.
+	Carousel struct {
+		Enabled     bool
+		Screens     []CarouselScreen
+		Pause       time.Duration
+		AlertScreen string // display.MethodRegistry name, empty for none
+	}
+	// Pins are RP2040 GPIO numbers.
 	I2C struct {
 		Frequency uint32
-		SDA       machine.Pin
-		SCL       machine.Pin
+		SDA       uint8
+		SCL       uint8
 	}
 	Buttons struct {
-		Button1 machine.Pin
-		Button2 machine.Pin
+		Button1  uint8
+		Button2  uint8
+		Gestures button.GestureConfig
+	}
+	// Outputs signal active alerts; hal.NoPin if not fitted.
+	Outputs struct {
+		LED    uint8
+		Buzzer uint8
 	}
+	Alerts struct {
+		Rules  []alert.Rule
+		Snooze time.Duration // how long a button press silences the alerts
+	}
+	Calibration Calibration
+	// Forecast drives the CO2 slope and the "time until ventilate" screen.
+	Forecast types.Forecast
 	Timeouts struct {
 		Startup time.Duration
 		Minute  time.Duration
 		Second  time.Duration
 	}
+	// Intervals of the periodic tasks. During Timeouts.Startup the sensors
+	// are read every Timeouts.Second instead of every Intervals.Sensors.
+	Intervals struct {
+		RTC      time.Duration
+		Sensors  time.Duration
+		Input    time.Duration
+		Watchdog time.Duration
+		Persist  time.Duration // minimum delay between config writes
+	}
+	Recovery struct {
+		Retries     int           // extra attempts per I2C transaction
+		Backoff     time.Duration // delay before the first retry, doubled after each
+		ReinitAfter int           // consecutive failed reads before a sensor is re-initialised
+	}
+	// TimeZone is a POSIX TZ rule such as "CET-1CEST,M3.5.0,M10.5.0/3" or a
+	// fixed offset such as "+02:00". The RTC and logs always use UTC.
+	TimeZone  string
+	Telemetry struct {
+		Format string // telemetry.FormatStrings name
+		Device string // identifies the unit in every record
+	}
+	Units struct {
+		Temperature types.TemperatureUnit // shown on the screens only
+	}
+	// HiddenScreens lists display.MethodRegistry names skipped by the
+	// buttons.
+	HiddenScreens []string
+	// Sensors lists sensor.Registry names, initialised in this order.
+	Sensors []string
+	// SensorMode is the acquisition mode of the sensors that have several.
+	// LowPower, SingleShot and PowerDown lower the average current of the
+	// SCD4x.
+	SensorMode          sensor.Mode
 	QueueCapacity       int
-	DefaultDisplayIndex int
+	DefaultDisplayIndex int // last selected screen, persisted
 }
 
 func DefaultConfig() Config {
 	cfg := Config{}
 	cfg.Display.Width = 128
 	cfg.Display.Height = 32
-	cfg.Display.Address = ssd1306.Address_128_32
-	cfg.I2C.Frequency = 400 * machine.KHz
-	cfg.I2C.SDA = machine.GP4
-	cfg.I2C.SCL = machine.GP5
-	cfg.Buttons.Button1 = machine.GP10
-	cfg.Buttons.Button2 = machine.GP11
+	cfg.Display.Address = 0x3C // ssd1306.Address_128_32
+	// Night mode has no effect by default: the day contrast is the one
+	// used before it was configurable and already as low as the night one,
+	// and the display is never turned off. Raise Display.Brightness or set
+	// Night.Off to use it.
+	cfg.Display.Brightness = 0x01 // the contrast used before it was configurable
+	cfg.Night.Brightness = 0x01
+	cfg.Night.Dim = Period{From: 21 * 60, Until: 7 * 60}
+	cfg.Night.Off = Period{} // never, the display stays on unless enabled
+	cfg.Night.Wake = 15 * time.Second
+	cfg.BurnIn = display.DefaultBurnInConfig()
+	cfg.Carousel.Screens = []CarouselScreen{
+		{Name: "RenderTime", Dwell: 20 * time.Second},
+		{Name: "RenderSparklineCO2", Dwell: 5 * time.Second},
+		{Name: "RenderVentilation", Dwell: 10 * time.Second},
+	}
+	cfg.Carousel.Pause = 2 * time.Minute
+	cfg.Carousel.AlertScreen = "RenderAlert"
+	cfg.I2C.Frequency = 400 * 1000
+	cfg.I2C.SDA = 4          // GP4
+	cfg.I2C.SCL = 5          // GP5
+	cfg.Buttons.Button1 = 10 // GP10
+	cfg.Buttons.Button2 = 11 // GP11
+	cfg.Buttons.Gestures = button.DefaultGestureConfig()
+	cfg.Outputs.LED = 25 // GP25, on-board LED
+	cfg.Outputs.Buzzer = hal.NoPin
+	cfg.Alerts.Rules = alert.DefaultRules()
+	cfg.Alerts.Snooze = 30 * time.Minute
+	cfg.Calibration = DefaultCalibration()
+	cfg.Forecast = types.DefaultForecast()
 	cfg.Timeouts.Startup = 1 * time.Minute
 	cfg.Timeouts.Minute = 1 * time.Minute
 	cfg.Timeouts.Second = 1 * time.Second
+	cfg.Intervals.RTC = 1 * time.Second
+	cfg.Intervals.Sensors = 1 * time.Minute
+	cfg.Intervals.Input = 50 * time.Millisecond
+	cfg.Intervals.Watchdog = 1 * time.Second
+	cfg.Intervals.Persist = 30 * time.Second
+	cfg.Recovery.Retries = 2
+	cfg.Recovery.Backoff = 5 * time.Millisecond
+	cfg.Recovery.ReinitAfter = 3
+	cfg.TimeZone = "UTC"
+	cfg.Telemetry.Format = "csv"
+	cfg.Telemetry.Device = "pico_co2"
+	cfg.Sensors = []string{"aht20", "ens160", "scd4x"}
 	cfg.QueueCapacity = 480
 	cfg.DefaultDisplayIndex = 0
 	return cfg
 }
 
-func (c Config) initI2C() error {
-	return machine.I2C0.Configure(machine.I2CConfig{
-		Frequency: c.I2C.Frequency,
-		SDA:       c.I2C.SDA,
-		SCL:       c.I2C.SCL,
-	})
-}
-
-func (c Config) initDisplay(bus drivers.I2C) (display.Renderer, error) {
-	disp := ssd1306.NewI2C(bus)
-	disp.Configure(ssd1306.Config{
-		Width:   c.Display.Width,
-		Height:  c.Display.Height,
-		Address: c.Display.Address,
-	})
-	// REDUCE BRIGHTNESS
-	// reduce contrast for night viewing
-	disp.Command(ssd1306.SETCONTRAST)
-	disp.Command(0x01)
-	// precharge period
-	disp.Command(ssd1306.SETPRECHARGE)
-	disp.Command(
-		0xE1,
-	) // 0xF1 default, 0xE1 for lower power, 0xD2 for even lower
-	// VCOMH deselect level
-	disp.Command(ssd1306.SETVCOMDETECT)
-	disp.Command(
-		0x30,
-	) // 0x20 default, 0x30 for lower power, 0x40 for even lower
-	return display.NewSSD1306Adapter(&disp), nil
-}
-
-type RawReadings struct {
-	CO2         uint16
-	Temperature float32
-	Humidity    float32
+func (c Config) boardConfig() hal.BoardConfig {
+	return hal.BoardConfig{
+		I2CFrequency: c.I2C.Frequency,
+		SDA:          c.I2C.SDA,
+		SCL:          c.I2C.SCL,
+		Button1:      c.Buttons.Button1,
+		Button2:      c.Buttons.Button2,
+		LED:          c.Outputs.LED,
+		Buzzer:       c.Outputs.Buzzer,
+	}
 }
 
 type Sensors struct {
-	aht20  *aht20.Device
-	ens160 *ens160.Device
-	scd4x  *scd4x.Device
+	list        []sensor.Sensor
+	reinitAfter int
+	watchdog    hal.Watchdog
 }
 
-func NewSensors(bus drivers.I2C) (*Sensors, error) {
-	s := &Sensors{}
-
-	if err := s.initAHT20(bus); err != nil {
-		return nil, fmt.Errorf("aht20 init: %w", err)
-	}
-
-	if err := s.initENS160(bus); err != nil {
-		return nil, fmt.Errorf("ens160 init: %w", err)
+// NewSensors builds and initialises the sensors listed in names.
+func NewSensors(names []string, bus drivers.I2C, clock hal.Clock) (*Sensors, error) {
+	list, err := sensor.Build(names, bus, clock)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.initSCD4x(bus); err != nil {
-		return nil, fmt.Errorf("scd4x init: %w", err)
+	for _, sn := range list {
+		if err := sn.Init(); err != nil {
+			return nil, fmt.Errorf("%s init: %w", sn.Name(), err)
+		}
+		if id, ok := sn.(sensor.Identifier); ok {
+			logln("sensor", sn.Name(), id.Identity())
+		}
 	}
 
-	return s, nil
+	return &Sensors{list: list}, nil
 }
 
-func (s *Sensors) initAHT20(bus drivers.I2C) error {
-	aht20Sensor := aht20.New(bus)
-	aht20Sensor.Reset()
-	aht20Sensor.Configure()
-	s.aht20 = &aht20Sensor
-	return nil
+// EnableReinit re-runs the init sequence of a sensor after every n
+// consecutive failed reads. The watchdog is fed before each re-init, since
+// init sequences take seconds.
+func (s *Sensors) EnableReinit(n int, wd hal.Watchdog) {
+	s.reinitAfter = n
+	s.watchdog = wd
 }
 
-func (s *Sensors) initENS160(bus drivers.I2C) error {
-	ens160Sensor := ens160.New(bus, ens160.DefaultAddress)
-	if err := ens160Sensor.Sleep(); err != nil {
-		return err
+// Read reads every sensor and records its health in readings. A failing
+// sensor does not prevent the others from being read.
+func (s *Sensors) Read(readings *types.Readings) *types.RawReadings {
+	raw := &types.RawReadings{}
+	for _, sn := range s.list {
+		before := raw.Valid
+		err := sn.Read(raw)
+		if err != nil {
+			err = fmt.Errorf("%s read: %w", sn.Name(), err)
+			raw.Valid = before
+		}
+		readings.RecordSensor(sn.Name(), raw.Valid&^before, err)
+
+		if err != nil && s.reinitAfter > 0 {
+			h := readings.Sensor(sn.Name())
+			if h.Failures%s.reinitAfter == 0 {
+				s.reinit(sn, h)
+			}
+		}
 	}
-	s.ens160 = ens160Sensor
-	return nil
+
+	return raw
 }
 
-func (s *Sensors) initSCD4x(bus drivers.I2C) error {
-	scd4xSensor := scd4x.New(bus)
-	time.Sleep(1500 * time.Millisecond)
-	if err := scd4xSensor.Configure(); err != nil {
+// Calibrate runs a forced recalibration of q on the first sensor that
+// supports it.
+func (s *Sensors) Calibrate(q types.Quantity, reference float32) error {
+	for _, sn := range s.list {
+		c, ok := sn.(sensor.Calibrator)
+		if !ok {
+			continue
+		}
+		err := c.Calibrate(q, reference)
+		if errors.Is(err, sensor.ErrNotCalibratable) {
+			continue
+		}
 		return err
 	}
+	return fmt.Errorf("%s: %w", q, sensor.ErrNotCalibratable)
+}
 
-	time.Sleep(1500 * time.Millisecond)
-
-	if err := scd4xSensor.StartPeriodicMeasurement(); err != nil {
-		return err
+// Identity returns the part or firmware version reported by the named
+// sensor, or "" if it reports none.
+func (s *Sensors) Identity(name string) string {
+	for _, sn := range s.list {
+		if id, ok := sn.(sensor.Identifier); ok && sn.Name() == name {
+			return id.Identity()
+		}
 	}
+	return ""
+}
 
-	time.Sleep(1500 * time.Millisecond)
+// Diagnose prints the raw values of every sensor that has them, or only of
+// the named one.
+func (s *Sensors) Diagnose(w io.Writer, name string) error {
+	found := false
+	for _, sn := range s.list {
+		d, ok := sn.(sensor.Diagnoser)
+		if !ok || name != "" && sn.Name() != name {
+			continue
+		}
+		found = true
+		fmt.Fprintf(w, "%s:\n", sn.Name())
+		if err := d.Diagnose(w); err != nil {
+			return fmt.Errorf("%s: %w", sn.Name(), err)
+		}
+	}
+	if !found {
+		return errors.New("no sensor with diagnostics")
+	}
+	return nil
+}
 
-	s.scd4x = scd4xSensor
+// SetMode switches every sensor with several acquisition modes to m.
+func (s *Sensors) SetMode(m sensor.Mode) error {
+	found := false
+	for _, sn := range s.list {
+		ms, ok := sn.(sensor.ModeSetter)
+		if !ok {
+			continue
+		}
+		if err := ms.SetMode(m); err != nil {
+			return fmt.Errorf("%s: %w", sn.Name(), err)
+		}
+		found = true
+	}
+	if !found && m != sensor.Periodic {
+		return fmt.Errorf("%s: %w", m, sensor.ErrModeUnsupported)
+	}
 	return nil
 }
 
-func (s *Sensors) Read() (*RawReadings, error) {
-	if err := s.aht20.Read(); err != nil {
-		return nil, fmt.Errorf("aht20 read: %w", err)
+// Prepare starts the on-demand measurements, or their next steps, and
+// returns how long until it is called again, or 0 once all of them can be
+// read.
+func (s *Sensors) Prepare() time.Duration {
+	var wait time.Duration
+	for _, sn := range s.list {
+		p, ok := sn.(sensor.Preparer)
+		if !ok {
+			continue
+		}
+		// A failure shows up in the health of the following read.
+		d, err := p.Prepare()
+		if err != nil {
+			logln("sensor", sn.Name(), "prepare failed:", err.Error())
+		}
+		wait = max(wait, d)
 	}
+	return wait
+}
 
-	co2, err := s.scd4x.ReadCO2()
-	if err != nil {
-		return nil, fmt.Errorf("scd4x read: %w", err)
+func (s *Sensors) reinit(sn sensor.Sensor, h *types.SensorHealth) {
+	if s.watchdog != nil {
+		s.watchdog.Update()
 	}
 
-	return &RawReadings{
-		CO2:         uint16(co2),
-		Temperature: s.aht20.Celsius(),
-		Humidity:    s.aht20.RelHumidity(),
-	}, nil
+	h.Reinits++
+	if err := sn.Init(); err != nil {
+		logln("sensor", sn.Name(), "re-init failed:", err.Error())
+		return
+	}
+	logln("sensor", sn.Name(), "re-initialised after", h.Failures, "failures")
 }
 
 type DisplayManager struct {
 	renderer     display.Renderer
+	burnIn       *display.BurnIn // nil without burn-in protection
 	currentIndex int
 }
 
@@ -197,169 +363,434 @@ func (dm *DisplayManager) PreviousDisplay() {
 	dm.currentIndex = (dm.currentIndex - 1 + len(display.MethodRegistry)) % len(display.MethodRegistry)
 }
 
-func (dm *DisplayManager) Render(readings *types.Readings) {
-	if readings.Error != "" {
-		display.RenderError(dm.renderer, readings)
-		return
-	}
+// SetDisplay selects the screen at index, wrapping around the registry.
+func (dm *DisplayManager) SetDisplay(index int) {
+	n := len(display.MethodRegistry)
+	dm.currentIndex = (index%n + n) % n
+}
+
+func (dm *DisplayManager) CurrentDisplay() int {
+	return dm.currentIndex
+}
 
+func (dm *DisplayManager) Render(readings *types.Readings) {
 	if dm.currentIndex >= len(display.MethodRegistry) {
 		dm.currentIndex = 0
 	}
 
-	renderMethod := display.MethodRegistry[dm.currentIndex]
-	renderMethod.Fn(dm.renderer, readings)
+	fn := display.MethodRegistry[dm.currentIndex].Fn
+	if readings.Error != "" {
+		fn = display.RenderError
+	}
+	dm.RenderWith(fn, readings)
+}
+
+// RenderWith draws fn instead of the current screen, e.g. the menu.
+func (dm *DisplayManager) RenderWith(fn func(display.Renderer, *types.Readings), readings *types.Readings) {
+	if dm.burnIn != nil {
+		dm.burnIn.Render(dm.renderer, fn, readings)
+		return
+	}
+	fn(dm.renderer, readings)
 }
 
 type App struct {
-	config         Config
-	sensors        *Sensors
-	displayManager *DisplayManager
-	button1        *button.TouchButton
-	button2        *button.TouchButton
-	ds3231         *ds3231.Device
+	config               Config
+	sensors              *Sensors
+	displayManager       *DisplayManager
+	gestures             *button.Gestures
+	ds3231               *ds3231.Device
+	watchdog             hal.Watchdog
+	clock                hal.Clock
+	events               *events.Bus
+	store                store.Store
+	configDirty          bool
+	console              io.ReadWriter
+	reset                func()
+	telemetry            *telemetry.Encoder
+	timeSource           TimeSource
+	zone                 *tz.Zone
+	alerts               *alert.Engine
+	led                  hal.OutputPin
+	buzzer               hal.OutputPin
+	alertTask            *scheduler.Task // LED and buzzer while an alert sounds
+	displayMode          displayMode
+	wakeUntil            time.Time       // display woken by a button press until
+	wakeTask             *scheduler.Task // turns the woken display off again
+	burnInTask           *scheduler.Task // redraws when the burn-in offset changes
+	carouselTask         *scheduler.Task
+	carouselNext         int // position in Carousel.Screens
+	carouselPausedUntil  time.Time
+	menu                 *menu.Menu // nil until the scheduler is created
+	menuTask             *scheduler.Task
+	menuUntil            time.Time // the menu closes when idle until then
+	calibrationReference int       // last CO2 reference used from the menu
 }
 
+// New loads the stored config on top of cfg, configures the board
+// peripherals and creates the application.
 func New(cfg Config) (*App, error) {
-	err := cfg.initI2C()
+	st, err := hal.OpenStore()
 	if err != nil {
-		return nil, fmt.Errorf("i2c init: %w", err)
+		logln("store unavailable, settings will not be saved:", err.Error())
+	} else if cfg, err = LoadConfig(st, cfg); err != nil {
+		logln("stored config ignored:", err.Error())
 	}
 
-	renderer, err := cfg.initDisplay(machine.I2C0)
+	board, err := hal.NewBoard(cfg.boardConfig())
 	if err != nil {
-		return nil, fmt.Errorf("display init: %w", err)
+		return nil, fmt.Errorf("board init: %w", err)
 	}
+	board.Store = st
 
-	sensors, err := NewSensors(machine.I2C0)
-	if err != nil {
-		return nil, fmt.Errorf("sensors init: %w", err)
-	}
-
-	ds3231Sensor := ds3231.New(machine.I2C0)
-	if ok := ds3231Sensor.Configure(); !ok {
-		return nil, fmt.Errorf("failed to configure DS3231 sensor")
-	}
+	return NewWithBoard(cfg, board)
+}
 
-	dt, _ := ds3231Sensor.ReadTime()
+// NewWithBoard creates the application on top of already configured
+// peripherals.
+func NewWithBoard(cfg Config, board *hal.Board) (*App, error) {
+	bus := hal.NewRecoveringI2C(
+		board.I2C,
+		board.Clock,
+		cfg.Recovery.Retries,
+		cfg.Recovery.Backoff,
+	)
+	bus.Log = logln
 
-	if dt.Year() > 2200 || dt.Year() < 2024 {
-		now := time.Date(2025, 12, 21, 14, 35, 0, 0, time.UTC)
-		ds3231Sensor.SetTime(now)
-		println("DS3231 time set to:", now.Format(time.DateTime))
+	renderer, err := cfg.initDisplay(bus)
+	if err != nil {
+		return nil, fmt.Errorf("display init: %w", err)
 	}
 
-	running := ds3231Sensor.IsRunning()
-	if !running {
-		err := ds3231Sensor.SetRunning(true)
-		if err != nil {
-			return nil, fmt.Errorf("ds3231 set running: %w", err)
+	sensors, err := NewSensors(cfg.Sensors, bus, board.Clock)
+	if err != nil {
+		return nil, fmt.Errorf("sensors init: %w", err)
+	}
+	sensors.EnableReinit(cfg.Recovery.ReinitAfter, board.Watchdog)
+	if cfg.SensorMode != sensor.Periodic {
+		if err := sensors.SetMode(cfg.SensorMode); err != nil {
+			logln("sensor mode:", err.Error())
 		}
 	}
 
-	return &App{
+	rtc := ds3231.New(bus)
+	a := &App{
 		config:         cfg,
 		sensors:        sensors,
 		displayManager: NewDisplayManager(renderer, cfg.DefaultDisplayIndex),
-		button1:        button.NewTouchButton(cfg.Buttons.Button1),
-		button2:        button.NewTouchButton(cfg.Buttons.Button2),
-		ds3231:         &ds3231Sensor,
-	}, nil
+		gestures: button.NewGestures(
+			button.NewTouchButton(board.Button1, board.Clock),
+			button.NewTouchButton(board.Button2, board.Clock),
+			board.Clock,
+			cfg.Buttons.Gestures,
+		),
+		ds3231:               &rtc,
+		watchdog:             board.Watchdog,
+		clock:                board.Clock,
+		events:               events.NewBus(),
+		store:                board.Store,
+		console:              board.Console,
+		reset:                board.Reset,
+		alerts:               alert.NewEngine(cfg.Alerts.Rules),
+		led:                  board.LED,
+		buzzer:               board.Buzzer,
+		calibrationReference: defaultCalibrationReference,
+	}
+	if c, err := sensors.compensator(); err == nil && cfg.Calibration.Pressure != 0 {
+		if err := c.SetPressure(cfg.Calibration.Pressure); err != nil {
+			logln("pressure compensation:", err.Error())
+		}
+	}
+	if err := a.setTimeZone(cfg.TimeZone); err != nil {
+		logln(err.Error(), "- using UTC")
+		a.zone = tz.UTC
+	}
+	if err := a.initRTC(); err != nil {
+		return nil, err
+	}
+	if err := a.setTelemetryFormat(cfg.Telemetry.Format); err != nil {
+		logln(err.Error(), "- telemetry disabled")
+		a.setTelemetryFormat(telemetry.FormatOff.String())
+	}
+	a.displayManager.burnIn = display.NewBurnIn(cfg.BurnIn, board.Clock)
+	a.setDisplayMode(nil, displayDay)
+	a.subscribeAlerts()
+	a.subscribeCarousel()
+	a.subscribeDisplay()
+	a.subscribeLogger()
+
+	return a, nil
+}
+
+// Events returns the bus on which the application publishes its events.
+func (a *App) Events() *events.Bus {
+	return a.events
+}
+
+func (a *App) publish(e events.Event) {
+	e.Time = a.clock.Now()
+	a.events.Publish(e)
 }
 
 func (a *App) Run() {
+	readings := a.newReadings()
+	tasks := a.newScheduler(readings)
+
+	a.watchdog.Start()
+
+	logln("starting loop")
+
+	tasks.Run()
+}
+
+// newReadings creates readings timestamped by the application clock.
+func (a *App) newReadings() *types.Readings {
 	readings := types.InitReadings(a.config.QueueCapacity)
+	readings.SetClock(a.clock)
+	readings.SetForecast(a.config.Forecast)
+	readings.TempUnit = a.config.Units.Temperature
+	return readings
+}
 
-	wd := machine.Watchdog
-	wd.Configure(machine.WatchdogConfig{
-		TimeoutMillis: machine.WatchdogMaxTimeout,
+// newScheduler registers the periodic jobs of the application.
+func (a *App) newScheduler(readings *types.Readings) *scheduler.Scheduler {
+	s := scheduler.New(a.clock)
+	s.OnOverrun = func(t *scheduler.Task, late time.Duration) {
+		logln("task", t.Name, "overrun by", late.String())
+	}
+
+	s.Every("watchdog", a.config.Intervals.Watchdog, a.watchdog.Update)
+	// The only task polling at Intervals.Input: buttons and the serial
+	// console.
+	var sh *shell.Shell
+	s.Every("input", a.config.Intervals.Input, func() {
+		a.handleInput(readings)
+		if sh != nil {
+			sh.Poll()
+		}
+	})
+	s.Every("rtc", a.config.Intervals.RTC, func() {
+		a.readTime(readings)
 	})
-	wd.Start()
 
-	println("starting loop")
+	var sensors, sensorsRead *scheduler.Task
+	sensorsRead = s.OnDemand("sensors-read", func() {
+		// A sensor may need another measurement first, such as the one
+		// it discards after a wake-up.
+		if d := a.sensors.Prepare(); d > 0 {
+			sensorsRead.TriggerAt(a.clock.Now().Add(d))
+			return
+		}
+		a.readSensors(readings)
+	})
+	sensors = s.Every("sensors", a.config.Timeouts.Second, func() {
+		// Sensors measuring on demand are read once their measurement is
+		// done, without blocking the other tasks meanwhile.
+		if !sensorsRead.Next().IsZero() {
+			return
+		}
+		if d := a.sensors.Prepare(); d > 0 {
+			sensorsRead.TriggerAt(a.clock.Now().Add(d))
+		} else {
+			a.readSensors(readings)
+		}
+		// Frequent reads only during the initial startup period
+		if a.startupDone(readings) {
+			sensors.SetPeriod(a.config.Intervals.Sensors)
+		}
+	})
 
-	for {
-		wd.Update()
+	sh = a.newShell(readings, sensors)
+	a.menu = a.newMenu(readings, sensors)
+	a.menuTask = s.OnDemand("menu-timeout", func() {
+		a.closeIdleMenu(readings)
+	})
+	if a.led != nil || a.buzzer != nil {
+		a.alertTask = s.OnDemand("alert-outputs", func() {
+			a.scheduleAlertOutputs(a.updateAlertOutputs(readings))
+		})
+	}
+	a.wakeTask = s.OnDemand("display-wake", func() {
+		a.endWake(readings)
+	})
+	a.burnInTask = s.OnDemand("burn-in", func() {
+		readings.IsDrawen = false
+	})
+	a.carouselTask = s.OnDemand("carousel", func() {
+		a.stepCarousel(readings)
+	})
+	a.carouselTask.Trigger()
+	s.Every("persist", a.config.Intervals.Persist, a.persistConfig)
 
-		a.handleInput(readings)
-		a.updateReadings(readings)
+	s.When("render", func() bool { return !readings.IsDrawen }, func() {
 		a.render(readings)
+	})
 
-		time.Sleep(50 * time.Millisecond)
-	}
+	return s
 }
 
 func (a *App) handleInput(readings *types.Readings) {
-	if a.button1.Consume() {
-		a.displayManager.PreviousDisplay()
-		readings.IsDrawen = false
+	a.gestures.Poll(func(g button.GestureEvent) {
+		a.handleGesture(readings, g)
+	})
+}
+
+func (a *App) handleGesture(readings *types.Readings, g button.GestureEvent) {
+	a.displayManager.burnIn.Activity()
+
+	// The first gesture while the display is off or an alert sounds only
+	// wakes the display and silences the alert.
+	woken := a.wakeDisplay(readings)
+	if a.snoozeAlerts(readings, "") > 0 || woken {
+		a.gestures.Suppress()
+		return
 	}
 
-	if a.button2.Consume() {
-		a.displayManager.NextDisplay()
+	if a.handleMenu(readings, g) {
+		return
+	}
+
+	a.publish(events.Event{
+		Kind:     events.ButtonPressed,
+		Readings: readings,
+		Button:   g.Button,
+		Gesture:  g.Gesture,
+	})
+}
+
+func (a *App) readTime(readings *types.Readings) {
+	curTime, err := a.ds3231.ReadTime()
+	if err != nil {
+		readings.Error = fmt.Sprintf("DS3231: %v", err)
 		readings.IsDrawen = false
+		return
+	}
+
+	logln("DS3231 time read:", curTime.Format(time.DateTime))
+	readings.Time.LastRead = a.clock.Now()
+	readings.Time.RTC = curTime
+	if trusted := a.timeSource.Trusted(); trusted != readings.Time.Trusted {
+		readings.Time.Trusted = trusted
+		invalidateTime(readings)
+	}
+	local := a.localTime(curTime)
+	if readings.Time.Minute != local.Minute() {
+		logln("DS3231 minute changed:", curTime.Format(time.DateTime))
+		readings.Time.Minute = local.Minute()
+		readings.Time.Hour = local.Hour()
+		a.publish(events.Event{Kind: events.MinuteChanged, Readings: readings})
 	}
 }
 
-func (a *App) updateReadings(readings *types.Readings) {
-	shouldUpdateTimeRead := readings.Time.LastRead.IsZero() ||
-		time.Since(readings.Time.LastRead) >= time.Duration(time.Second)
+func (a *App) readSensors(readings *types.Readings) {
+	raw := a.sensors.Read(readings)
+	readings.Add(*raw)
+	if readings.AllSensorsFailed() {
+		readings.Error = readings.SensorErrors()
+	}
 
-	if shouldUpdateTimeRead {
-		curTime, err := a.ds3231.ReadTime()
-		if err != nil {
-			readings.Error = fmt.Sprintf("DS3231: %v", err)
-			readings.IsDrawen = false
-		} else {
-			println("DS3231 time read:", curTime.Format(time.DateTime))
-			readings.Time.LastRead = time.Now()
-			if readings.Time.Minute != curTime.Minute() {
-				println("DS3231 minute changed:", curTime.Format(time.DateTime))
-				readings.Time.Minute = curTime.Minute()
-				readings.Time.Hour = curTime.Hour()
-				readings.IsDrawen = false
-			}
+	for i := range readings.Sensors {
+		if h := &readings.Sensors[i]; h.State != types.SensorOK {
+			a.publish(events.Event{Kind: events.SensorError, Readings: readings, Sensor: h})
 		}
 	}
+	a.publish(events.Event{Kind: events.ReadingAdded, Readings: readings, Raw: *raw})
+}
+
+func (a *App) setSensorMode(m sensor.Mode) error {
+	a.watchdog.Update()
+	if err := a.sensors.SetMode(m); err != nil {
+		return err
+	}
+	a.config.SensorMode = m
+	a.configChanged()
+	return nil
+}
 
-	shouldUpdateSensorRead := cmp.Or(
-		// First ever reading
-		readings.LastUpdateAt.IsZero(),
-		// Initial startup period
-		time.Since(readings.LastUpdateAt) >= time.Duration(time.Second) &&
-			time.Since(readings.FirstReadingAt) < time.Duration(time.Minute),
-		// Regular update interval
-		time.Since(readings.LastUpdateAt) >= time.Duration(time.Minute),
-	)
+func (a *App) startupDone(readings *types.Readings) bool {
+	return !readings.FirstReadingAt.IsZero() &&
+		a.clock.Since(readings.FirstReadingAt) >= a.config.Timeouts.Startup
+}
 
-	if shouldUpdateSensorRead {
-		if raw, err := a.sensors.Read(); err != nil {
-			readings.Error = err.Error()
-			readings.IsDrawen = false
-		} else {
-			readings.AddReadings(
-				raw.CO2,
-				raw.Temperature,
-				raw.Humidity,
-			)
-			fmt.Printf("%s, time: %02d:%02d, CO2: %d ppm, T: %.2f °C, H: %.2f %%, co2 len: %d, temp len: %d, hum len: %d\n",
-			    time.Now().Format(time.DateTime),
-				readings.Time.Hour,
-				readings.Time.Minute,
-				raw.CO2, raw.Temperature, raw.Humidity,
-				readings.History.CO2.Len(),
-				readings.History.Temperature.Len(),
-				readings.History.Humidity.Len(),
-			)
-			readings.Error = ""
-			readings.IsDrawen = false
+// rtcNow extrapolates the last RTC read with the system clock.
+func (a *App) rtcNow(readings *types.Readings) time.Time {
+	if readings.Time.RTC.IsZero() {
+		return a.clock.Now()
+	}
+	return readings.Time.RTC.Add(a.clock.Since(readings.Time.LastRead))
+}
+
+// setTelemetryFormat selects the format of the records written to the
+// console and writes the CSV header right away.
+func (a *App) setTelemetryFormat(name string) error {
+	format, ok := telemetry.ParseFormat(name)
+	if !ok {
+		return fmt.Errorf("unknown telemetry format %q", name)
+	}
+
+	var w io.Writer = os.Stdout
+	if a.console != nil {
+		w = a.console
+	}
+	a.telemetry = telemetry.NewEncoder(w, format)
+	// Parsers that start reading now need the header.
+	return a.telemetry.WriteHeader()
+}
+
+// stepScreen selects the next screen in direction dir, 1 or -1, that is
+// not hidden.
+func (a *App) stepScreen(dir int) {
+	n := len(display.MethodRegistry)
+	index := a.displayManager.CurrentDisplay()
+	for range n {
+		index = ((index+dir)%n + n) % n
+		if !a.screenHidden(index) {
+			a.selectScreen(index)
+			return
 		}
 	}
 }
 
+// selectScreen shows the screen at index and remembers it across reboots.
+func (a *App) selectScreen(index int) {
+	a.displayManager.SetDisplay(index)
+	a.config.DefaultDisplayIndex = a.displayManager.CurrentDisplay()
+	a.configChanged()
+	a.pauseCarousel()
+}
+
+// configChanged marks the config to be written by the next persist task.
+// Writes are deferred to limit flash wear when settings change in bursts.
+func (a *App) configChanged() {
+	a.configDirty = a.store != nil
+}
+
+func (a *App) persistConfig() {
+	if !a.configDirty {
+		return
+	}
+	if err := SaveConfig(a.store, a.config); err != nil {
+		logln("config save failed:", err.Error())
+		return
+	}
+	a.configDirty = false
+}
+
 func (a *App) render(readings *types.Readings) {
+	// Redrawn when the display is turned on again.
+	if a.displayMode == displayOff {
+		readings.IsDrawen = true
+		return
+	}
 	if !readings.IsDrawen {
-		a.displayManager.Render(readings)
+		if a.menu != nil && a.menu.IsOpen() {
+			a.displayManager.RenderWith(a.menu.Render, readings)
+		} else {
+			a.displayManager.Render(readings)
+		}
 		readings.IsDrawen = true
+		if a.burnInTask != nil {
+			a.burnInTask.TriggerAt(a.displayManager.burnIn.NextChange(a.clock.Now()))
+		}
 	}
 }
@@ -0,0 +1,408 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"pico_co2/internal/display"
+	"pico_co2/internal/events"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/sensor"
+	"pico_co2/internal/types"
+	"pico_co2/pkg/ens160"
+)
+
+const (
+	aht20Addr  = 0x38
+	ens160Addr = 0x53
+	scd4xAddr  = 0x62
+	ds3231Addr = 0x68
+)
+
+func bcd(v int) byte {
+	return byte(v/10<<4 | v%10)
+}
+
+// fakeAHT20 always reports a calibrated, idle sensor with ~50% RH and ~25 °C.
+func fakeAHT20(w, r []byte) error {
+	if len(r) >= 7 {
+		copy(r, []byte{0x08, 0x80, 0x00, 0x06, 0x00, 0x00, 0x00})
+	}
+	return nil
+}
+
+// fakeSCD4x answers "data ready" and a fixed 800 ppm measurement.
+func fakeSCD4x(w, r []byte) error {
+	switch len(r) {
+	case 3:
+		copy(r, []byte{0x80, 0x06, 0x04}) // data ready
+	case 9:
+		copy(r, []byte{0x03, 0x20, 0x2A, 0x66, 0x66, 0x93, 0x80, 0x00, 0xA2})
+	}
+	return nil
+}
+
+// fakeENS160 reports new data with AQI 2, TVOC 120 ppb and eCO2 600 ppm and
+// records the compensation data written to it.
+type fakeENS160 struct {
+	envData []byte
+}
+
+func (f *fakeENS160) tx(w, r []byte) error {
+	switch {
+	case len(w) == 1 && w[0] == 0x00 && len(r) == 2:
+		copy(r, []byte{0x60, 0x01}) // part ID
+	case len(w) == 1 && w[0] == 0x4C && len(r) == 3:
+		copy(r, []byte{5, 4, 6}) // firmware version
+	case len(w) == 1 && w[0] == 0x30 && len(r) == 4:
+		copy(r, f.envData) // compensation applied
+	case len(w) == 1 && w[0] == 0x48 && len(r) == 8:
+		copy(r, []byte{0x00, 0x88, 0, 0, 0, 0, 0x00, 0xA0}) // 2^17 and 2^20 ohm
+	case len(w) == 5 && w[0] == 0x13:
+		f.envData = append([]byte(nil), w[1:]...)
+	case len(w) == 1 && w[0] == 0x20 && len(r) == 1:
+		r[0] = 0x02
+	case len(w) == 1 && w[0] == 0x21 && len(r) == 5:
+		copy(r, []byte{2, 120, 0, 0x58, 0x02})
+	}
+	return nil
+}
+
+// fakeDS3231 returns the time of clock when its time registers are read.
+func fakeDS3231(clock hal.Clock) hal.FakeDevice {
+	return func(w, r []byte) error {
+		if len(w) == 1 && w[0] == 0x00 && len(r) == 7 {
+			now := clock.Now()
+			copy(r, []byte{
+				bcd(now.Second()), bcd(now.Minute()), bcd(now.Hour()),
+				bcd(int(now.Weekday())), bcd(now.Day()), bcd(int(now.Month())),
+				bcd(now.Year() - 2000),
+			})
+		}
+		return nil
+	}
+}
+
+func newTestApp(t *testing.T) (*App, *hal.Board) {
+	t.Helper()
+	a, board, _ := newTestAppWithENS160(t)
+	return a, board
+}
+
+func newTestAppWithENS160(t *testing.T) (*App, *hal.Board, *fakeENS160) {
+	t.Helper()
+	board, ens := newTestBoard()
+
+	a, err := NewWithBoard(DefaultConfig(), board)
+	if err != nil {
+		t.Fatalf("NewWithBoard: %v", err)
+	}
+	return a, board, ens
+}
+
+// newTestBoard returns a board with fake sensors and an RTC following the
+// fake clock.
+func newTestBoard() (*hal.Board, *fakeENS160) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 14, 23, 0, 0, time.UTC))
+	bus := hal.NewFakeI2C()
+	bus.Attach(aht20Addr, fakeAHT20)
+	bus.Attach(scd4xAddr, fakeSCD4x)
+	ens := &fakeENS160{}
+	bus.Attach(ens160Addr, ens.tx)
+	bus.Attach(ds3231Addr, fakeDS3231(clock))
+
+	board := &hal.Board{
+		I2C:      bus,
+		Button1:  &hal.FakePin{},
+		Button2:  &hal.FakePin{},
+		Watchdog: &hal.FakeWatchdog{},
+		Clock:    clock,
+	}
+	return board, ens
+}
+
+func TestAppFirstPassReadsSensors(t *testing.T) {
+	a, board := newTestApp(t)
+	readings := a.newReadings()
+
+	a.newScheduler(readings).RunPending()
+
+	if readings.Error != "" {
+		t.Fatalf("unexpected error: %s", readings.Error)
+	}
+	if readings.Raw.CO2 != 800 {
+		t.Errorf("CO2 = %d, want 800", readings.Raw.CO2)
+	}
+	if readings.Raw.Temperature < 24 || readings.Raw.Temperature > 26 {
+		t.Errorf("Temperature = %.2f, want ~25", readings.Raw.Temperature)
+	}
+	now := board.Clock.Now()
+	if readings.Time.Hour != now.Hour() || readings.Time.Minute != now.Minute() {
+		t.Errorf("time = %02d:%02d, want %02d:%02d",
+			readings.Time.Hour, readings.Time.Minute, now.Hour(), now.Minute())
+	}
+	if !readings.IsDrawen {
+		t.Error("expected readings to be rendered")
+	}
+	if wd := board.Watchdog.(*hal.FakeWatchdog); wd.Updates != 1 {
+		t.Errorf("watchdog updates = %d, want 1", wd.Updates)
+	}
+}
+
+func TestAppReadsENS160WithCompensation(t *testing.T) {
+	a, _, ens := newTestAppWithENS160(t)
+	readings := a.newReadings()
+
+	a.readSensors(readings)
+
+	if readings.Raw.TVOC != 120 || readings.Raw.ECO2 != 600 || readings.Raw.AQI != 1 {
+		t.Errorf("TVOC/eCO2/AQI = %d/%d/%d, want 120/600/1",
+			readings.Raw.TVOC, readings.Raw.ECO2, readings.Raw.AQI)
+	}
+	if readings.History.TVOC.Len() != 1 || readings.History.AQI.Len() != 1 {
+		t.Errorf("expected TVOC and AQI history entries")
+	}
+
+	// 25 °C and 50 %RH as Kelvin*64 and %RH*512, little endian.
+	want := []byte{0x89, 0x4A, 0x00, 0x64}
+	if string(ens.envData) != string(want) {
+		t.Errorf("env data = % X, want % X", ens.envData, want)
+	}
+}
+
+func TestAppKeepsWorkingSensorsOnFailure(t *testing.T) {
+	a, board := newTestApp(t)
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		return errors.New("nack")
+	})
+	readings := a.newReadings()
+
+	a.readSensors(readings)
+
+	if readings.Error != "" {
+		t.Errorf("unexpected error: %s", readings.Error)
+	}
+	if !readings.Raw.Valid.Has(types.Temperature) || readings.Raw.Valid.Has(types.CO2) {
+		t.Errorf("valid = %b, want temperature without CO2", readings.Raw.Valid)
+	}
+	if h := readings.Sensor("scd4x"); h == nil || h.State != types.SensorFailed {
+		t.Errorf("scd4x health = %+v, want failed", h)
+	}
+}
+
+func TestAppReportsAllSensorsFailedWithoutSubscribers(t *testing.T) {
+	a, board := newTestApp(t)
+	a.events = events.NewBus()
+	bus := board.I2C.(*hal.FakeI2C)
+	for _, addr := range []uint16{aht20Addr, ens160Addr, scd4xAddr} {
+		bus.Attach(addr, func(w, r []byte) error { return errors.New("nack") })
+	}
+	readings := a.newReadings()
+
+	a.readSensors(readings)
+	if readings.Error == "" {
+		t.Error("Expected the sensor errors in the readings")
+	}
+}
+
+func TestAppRejectsWrongENS160PartID(t *testing.T) {
+	board, _ := newTestBoard()
+	board.I2C.(*hal.FakeI2C).Attach(ens160Addr, func(w, r []byte) error {
+		if len(w) == 1 && w[0] == 0x00 && len(r) == 2 {
+			copy(r, []byte{0x61, 0x01})
+		}
+		return nil
+	})
+
+	_, err := NewWithBoard(DefaultConfig(), board)
+	var idErr *ens160.PartIDError
+	if !errors.As(err, &idErr) || idErr.PartID != 0x0161 {
+		t.Errorf("Expected a part ID error, got %v", err)
+	}
+}
+
+func TestAppUsesSCD4xTemperatureWithoutAHT20(t *testing.T) {
+	board, _ := newTestBoard()
+	cfg := DefaultConfig()
+	cfg.Sensors = []string{"scd4x"}
+	a, err := NewWithBoard(cfg, board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readings := a.newReadings()
+
+	a.readSensors(readings)
+
+	raw := readings.Raw
+	if raw.CO2 != 800 || raw.Temperature < 24.9 || raw.Temperature > 25 || raw.Humidity != 50 {
+		t.Errorf("Expected 800 ppm, 25 °C and 50 %%RH, got %d, %.2f, %.2f", raw.CO2, raw.Temperature, raw.Humidity)
+	}
+}
+
+func TestAppSingleShotReadsAfterMeasurement(t *testing.T) {
+	a, board := newTestApp(t)
+	var shots []time.Time
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		if len(w) == 2 && w[0] == 0x21 && w[1] == 0x9D {
+			shots = append(shots, board.Clock.Now())
+		}
+		return fakeSCD4x(w, r)
+	})
+	if err := a.setSensorMode(sensor.SingleShot); err != nil {
+		t.Fatal(err)
+	}
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+
+	start := board.Clock.Now()
+	for board.Clock.Since(start) < 8*time.Second {
+		board.Clock.Sleep(s.RunPending())
+	}
+
+	// The sensor task waits for the pending shot and starts the next one
+	// right after the read, which waits 1 ms each for the data-ready
+	// status and the measurement.
+	const readTime = 2 * time.Millisecond
+	if len(shots) != 2 || !shots[0].Equal(start) || shots[1].Sub(shots[0]) != 5*time.Second+readTime {
+		t.Fatalf("Expected shots 5s apart from the start, got %v", shots)
+	}
+	if readings.Raw.CO2 != 800 || readings.FirstReadingAt.Sub(shots[0]) != 5*time.Second+readTime {
+		t.Errorf("Expected 800 ppm read 5s after the shot, got %d at %s",
+			readings.Raw.CO2, readings.FirstReadingAt.Sub(shots[0]))
+	}
+}
+
+func TestAppPowerDownDiscardsFirstShot(t *testing.T) {
+	a, board := newTestApp(t)
+	var cmds []string
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		if len(w) >= 2 {
+			cmds = append(cmds, fmt.Sprintf("%02X%02X", w[0], w[1]))
+		}
+		if len(w) == 2 && w[0] == 0x36 && w[1] == 0xF6 {
+			return errors.New("nack") // the wake-up is never acknowledged
+		}
+		return fakeSCD4x(w, r)
+	})
+	if err := a.setSensorMode(sensor.PowerDown); err != nil {
+		t.Fatal(err)
+	}
+	// stop, get variant, power down
+	if want := []string{"3F86", "202F", "36E0"}; !slices.Equal(cmds, want) {
+		t.Fatalf("Expected commands %v, got %v", want, cmds)
+	}
+	cmds = nil
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+
+	start := board.Clock.Now()
+	for readings.FirstReadingAt.IsZero() && board.Clock.Since(start) < time.Minute {
+		board.Clock.Sleep(s.RunPending())
+	}
+
+	// wake up, check the serial number, shoot, drop the first measurement,
+	// shoot again, read it once ready and power down
+	want := []string{"36F6", "3682", "219D", "EC05", "219D", "E4B8", "EC05", "36E0"}
+	if !slices.Equal(cmds, want) {
+		t.Errorf("Expected commands %v, got %v", want, cmds)
+	}
+	if got := readings.FirstReadingAt.Sub(start).Round(time.Second); readings.Raw.CO2 != 800 || got != 10*time.Second {
+		t.Errorf("Expected 800 ppm read after two shots, got %d after %s", readings.Raw.CO2, got)
+	}
+}
+
+func TestAppRejectsSingleShotOnSCD40(t *testing.T) {
+	a, board := newTestApp(t)
+	var cmds []string
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		if len(w) == 2 {
+			cmds = append(cmds, fmt.Sprintf("%02X%02X", w[0], w[1]))
+		}
+		if len(r) == 3 {
+			copy(r, []byte{0x00, 0x00, 0x81}) // variant SCD40
+		}
+		return nil
+	})
+
+	if err := a.setSensorMode(sensor.SingleShot); !errors.Is(err, sensor.ErrModeUnsupported) {
+		t.Fatalf("Expected ErrModeUnsupported, got %v", err)
+	}
+	if a.config.SensorMode != sensor.Periodic {
+		t.Errorf("Expected the periodic mode to stay, got %s", a.config.SensorMode)
+	}
+	// stop, get variant, start periodic again
+	if want := []string{"3F86", "202F", "21B1"}; !slices.Equal(cmds, want) {
+		t.Errorf("Expected commands %v, got %v", want, cmds)
+	}
+}
+
+func TestSensorsReinitAfterRepeatedFailures(t *testing.T) {
+	a, board := newTestApp(t)
+	bus := board.I2C.(*hal.FakeI2C)
+	bus.Attach(scd4xAddr, func(w, r []byte) error {
+		return errors.New("nack")
+	})
+	readings := a.newReadings()
+
+	n := a.config.Recovery.ReinitAfter
+	for range n - 1 {
+		a.sensors.Read(readings)
+	}
+	if h := readings.Sensor("scd4x"); h.Reinits != 0 {
+		t.Fatalf("reinits after %d failures = %d, want 0", n-1, h.Reinits)
+	}
+
+	a.sensors.Read(readings)
+	h := readings.Sensor("scd4x")
+	if h.Reinits != 1 {
+		t.Errorf("reinits = %d, want 1", h.Reinits)
+	}
+	if bus.Clears() != 0 {
+		t.Error("expected no bus clear for a NACK")
+	}
+
+	bus.Attach(scd4xAddr, fakeSCD4x)
+	a.sensors.Read(readings)
+	if h := readings.Sensor("scd4x"); h.State != types.SensorOK {
+		t.Errorf("state after re-init = %v, want ok", h.State)
+	}
+}
+
+func TestAppHandleInput(t *testing.T) {
+	a, board := newTestApp(t)
+	readings := a.newReadings()
+	readings.IsDrawen = true
+
+	board.Button2.(*hal.FakePin).Press()
+	a.handleInput(readings)
+
+	if a.displayManager.currentIndex != 1 {
+		t.Errorf("index after next = %d, want 1", a.displayManager.currentIndex)
+	}
+	if readings.IsDrawen {
+		t.Error("expected redraw after button press")
+	}
+
+	// Presses inside the debounce window are ignored.
+	board.Button1.(*hal.FakePin).Press()
+	board.Clock.Sleep(10 * time.Millisecond)
+	board.Button1.(*hal.FakePin).Press()
+	a.handleInput(readings)
+	a.handleInput(readings)
+
+	if a.displayManager.currentIndex != 0 {
+		t.Errorf("index after previous = %d, want 0", a.displayManager.currentIndex)
+	}
+
+	board.Clock.Sleep(100 * time.Millisecond)
+	board.Button1.(*hal.FakePin).Press()
+	a.handleInput(readings)
+
+	want := len(display.MethodRegistry) - 1
+	if a.displayManager.currentIndex != want {
+		t.Errorf("index after wrap = %d, want %d", a.displayManager.currentIndex, want)
+	}
+}
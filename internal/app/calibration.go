@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"pico_co2/internal/sensor"
+	"pico_co2/internal/shell"
+	"pico_co2/internal/types"
+)
+
+// Calibration records the CO2 sensor settings. AutoCalibration and
+// Altitude are persisted by the sensor itself when they are changed;
+// Pressure is not and is sent again at startup.
+type Calibration struct {
+	AutoCalibration bool
+	Altitude        uint16    // metres above sea level
+	Pressure        uint16    // hPa, 0 to compensate by altitude
+	Last            time.Time // last forced recalibration (RTC time), zero if never or unknown
+	LastReference   uint16    // ppm of the last forced recalibration, 0 if never
+}
+
+// DefaultCalibration matches the factory settings of the SCD4x.
+func DefaultCalibration() Calibration {
+	return Calibration{AutoCalibration: true}
+}
+
+// compensator returns the first sensor with CO2 pressure compensation.
+func (s *Sensors) compensator() (sensor.CO2Compensator, error) {
+	for _, sn := range s.list {
+		if c, ok := sn.(sensor.CO2Compensator); ok {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", types.CO2, sensor.ErrNotCalibratable)
+}
+
+// calibrateQuantity runs a forced recalibration of q and records when it
+// was done for CO2. The date is only recorded while the time is trusted.
+func (a *App) calibrateQuantity(readings *types.Readings, q types.Quantity, reference float32) error {
+	a.watchdog.Update()
+	if err := a.sensors.Calibrate(q, reference); err != nil {
+		return err
+	}
+	if q == types.CO2 {
+		a.config.Calibration.Last = time.Time{}
+		if a.timeSource.Trusted() {
+			a.config.Calibration.Last = a.rtcNow(readings).UTC()
+		}
+		a.config.Calibration.LastReference = uint16(reference)
+		a.configChanged()
+	}
+	return nil
+}
+
+func (a *App) setAutoCalibration(on bool) error {
+	return a.compensate(func(c sensor.CO2Compensator) error {
+		if err := c.SetAutoCalibration(on); err != nil {
+			return err
+		}
+		a.config.Calibration.AutoCalibration = on
+		return nil
+	})
+}
+
+func (a *App) setAltitude(metres uint16) error {
+	return a.compensate(func(c sensor.CO2Compensator) error {
+		if err := c.SetAltitude(metres); err != nil {
+			return err
+		}
+		a.config.Calibration.Altitude = metres
+		return nil
+	})
+}
+
+func (a *App) setPressure(hPa uint16) error {
+	return a.compensate(func(c sensor.CO2Compensator) error {
+		if err := c.SetPressure(hPa); err != nil {
+			return err
+		}
+		a.config.Calibration.Pressure = hPa
+		return nil
+	})
+}
+
+// compensate changes a setting of the CO2 sensor and saves the config.
+// The sensor stops measuring for about a second meanwhile.
+func (a *App) compensate(fn func(sensor.CO2Compensator) error) error {
+	c, err := a.sensors.compensator()
+	if err != nil {
+		return err
+	}
+	a.watchdog.Update()
+	if err := fn(c); err != nil {
+		return err
+	}
+	a.configChanged()
+	return nil
+}
+
+// calibrateCommand shows the calibration settings or changes one of them.
+func (a *App) calibrateCommand(w io.Writer, readings *types.Readings, args []string) error {
+	cal := &a.config.Calibration
+	if len(args) == 0 {
+		fmt.Fprintf(w, "asc         %s\n", onOff(cal.AutoCalibration))
+		fmt.Fprintf(w, "altitude    %d m\n", cal.Altitude)
+		if cal.Pressure != 0 {
+			fmt.Fprintf(w, "pressure    %d hPa\n", cal.Pressure)
+		} else {
+			fmt.Fprintln(w, "pressure    off")
+		}
+		fmt.Fprintf(w, "last        %s\n", a.formatCalibration())
+		return nil
+	}
+	if len(args) != 2 {
+		return shell.ErrUsage
+	}
+
+	switch args[0] {
+	case "asc":
+		if args[1] != "on" && args[1] != "off" {
+			return shell.ErrUsage
+		}
+		return a.setAutoCalibration(args[1] == "on")
+	case "altitude":
+		m, err := strconv.ParseUint(args[1], 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid altitude %q", args[1])
+		}
+		return a.setAltitude(uint16(m))
+	case "pressure":
+		if args[1] == "off" {
+			return a.setPressure(0)
+		}
+		p, err := strconv.ParseUint(args[1], 10, 16)
+		if err != nil || p == 0 {
+			return fmt.Errorf("invalid pressure %q", args[1])
+		}
+		return a.setPressure(uint16(p))
+	}
+
+	q, ok := types.ParseQuantity(args[0])
+	if !ok {
+		return fmt.Errorf("unknown quantity %q", args[0])
+	}
+	ref, err := strconv.ParseFloat(args[1], 32)
+	if err != nil {
+		return fmt.Errorf("invalid reference value %q", args[1])
+	}
+	if err := a.calibrateQuantity(readings, q, float32(ref)); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s calibrated to %s\n", q, args[1])
+	return nil
+}
+
+// formatCalibration describes the last forced recalibration in local time.
+func (a *App) formatCalibration() string {
+	cal := a.config.Calibration
+	if cal.LastReference == 0 {
+		return "never"
+	}
+	date := "unknown date"
+	if !cal.Last.IsZero() {
+		date = a.localTime(cal.Last).Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s to %d ppm", date, cal.LastReference)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
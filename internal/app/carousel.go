@@ -0,0 +1,90 @@
+package app
+
+import (
+	"time"
+
+	"pico_co2/internal/display"
+	"pico_co2/internal/events"
+	"pico_co2/internal/types"
+)
+
+// carouselAlertRecheck is how often the carousel checks whether the alert
+// it shows was snoozed or cleared.
+const carouselAlertRecheck = 10 * time.Second
+
+// CarouselScreen is a screen shown by the carousel and how long it stays.
+type CarouselScreen struct {
+	Name  string // display.MethodRegistry name
+	Dwell time.Duration
+}
+
+// subscribeCarousel moves the carousel to or away from the alert screen as
+// soon as an alert changes.
+func (a *App) subscribeCarousel() {
+	a.events.Subscribe(func(e events.Event) {
+		if a.carouselTask != nil {
+			a.carouselTask.Trigger()
+		}
+	}, events.AlertChanged)
+}
+
+// pauseCarousel keeps a manually selected screen for Carousel.Pause.
+func (a *App) pauseCarousel() {
+	if !a.config.Carousel.Enabled {
+		return
+	}
+	a.carouselPausedUntil = a.clock.Now().Add(a.config.Carousel.Pause)
+	if a.carouselTask != nil {
+		a.carouselTask.TriggerAt(a.carouselPausedUntil)
+	}
+}
+
+// stepCarousel shows the next screen of the carousel, or the alert screen
+// while an alert that is not snoozed is active, and schedules the next
+// step. The selected screen is not persisted.
+func (a *App) stepCarousel(readings *types.Readings) {
+	c := a.config.Carousel
+	if !c.Enabled {
+		return
+	}
+
+	now := a.clock.Now()
+	if index, ok := display.ScreenIndex(c.AlertScreen); ok && readings.Alert() != nil {
+		a.showCarouselScreen(readings, index)
+		a.carouselTask.TriggerAt(now.Add(carouselAlertRecheck))
+		return
+	}
+
+	if now.Before(a.carouselPausedUntil) {
+		a.carouselTask.TriggerAt(a.carouselPausedUntil)
+		return
+	}
+
+	// Skip entries that do not name a screen.
+	for range c.Screens {
+		s := c.Screens[a.carouselNext%len(c.Screens)]
+		a.carouselNext = (a.carouselNext + 1) % len(c.Screens)
+		if index, ok := display.ScreenIndex(s.Name); ok && s.Dwell > 0 {
+			a.showCarouselScreen(readings, index)
+			a.carouselTask.TriggerAt(now.Add(s.Dwell))
+			return
+		}
+	}
+}
+
+func (a *App) showCarouselScreen(readings *types.Readings, index int) {
+	if a.displayManager.CurrentDisplay() != index {
+		a.displayManager.SetDisplay(index)
+		readings.IsDrawen = false
+	}
+}
+
+// setCarousel turns the carousel on or off.
+func (a *App) setCarousel(on bool) {
+	a.config.Carousel.Enabled = on
+	a.carouselPausedUntil = time.Time{}
+	a.configChanged()
+	if on && a.carouselTask != nil {
+		a.carouselTask.Trigger()
+	}
+}
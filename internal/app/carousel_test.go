@@ -0,0 +1,69 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/display"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+)
+
+func TestCarousel(t *testing.T) {
+	a, board := newTestApp(t)
+	a.config.Carousel.Enabled = true
+	a.config.Carousel.Screens = []CarouselScreen{
+		{Name: "RenderTime", Dwell: 20 * time.Second},
+		{Name: "NoSuchScreen", Dwell: time.Second},
+		{Name: "RenderSparklineCO2", Dwell: 5 * time.Second},
+	}
+	a.alerts.SetRules(nil)
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+
+	run := func(d time.Duration) {
+		end := board.Clock.Now().Add(d)
+		for board.Clock.Now().Before(end) {
+			board.Clock.Sleep(s.RunPending())
+		}
+	}
+	screen := func() string {
+		return display.MethodRegistry[a.displayManager.CurrentDisplay()].Name
+	}
+
+	run(time.Second)
+	if screen() != "RenderTime" {
+		t.Fatalf("Expected RenderTime first, got %s", screen())
+	}
+	run(20 * time.Second)
+	if screen() != "RenderSparklineCO2" {
+		t.Errorf("Expected RenderSparklineCO2 after 20s, got %s", screen())
+	}
+	run(5 * time.Second)
+	if screen() != "RenderTime" {
+		t.Errorf("Expected RenderTime after 5s, got %s", screen())
+	}
+
+	// A manual change pauses the carousel and is persisted.
+	board.Button2.(*hal.FakePin).Press()
+	run(a.config.Carousel.Pause - time.Second)
+	manual := a.config.DefaultDisplayIndex
+	if a.displayManager.CurrentDisplay() != manual {
+		t.Errorf("Expected the manual screen %d during the pause, got %s", manual, screen())
+	}
+	run(2 * time.Second)
+	if a.displayManager.CurrentDisplay() == manual || a.config.DefaultDisplayIndex != manual {
+		t.Errorf("Expected the carousel to resume without persisting, got %s", screen())
+	}
+
+	a.alerts.SetRules([]alert.Rule{{
+		Name: "co2", Metric: alert.MetricCO2, Enter: 700, Exit: 600,
+		Severity: types.SeverityWarning,
+	}})
+	a.readSensors(readings)
+	run(time.Second)
+	if screen() != "RenderAlert" {
+		t.Errorf("Expected the alert screen while an alert is active, got %s", screen())
+	}
+}
@@ -0,0 +1,454 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/display"
+	"pico_co2/internal/sensor"
+	"pico_co2/internal/shell"
+	"pico_co2/internal/types"
+	"pico_co2/pkg/scheduler"
+)
+
+const (
+	minSensorInterval = 5 * time.Second // SCD4x periodic measurement rate
+	maxSensorInterval = time.Hour
+
+	timeSetLayout = "2006-01-02T15:04"
+)
+
+// newShell returns the serial command shell, or nil if the board has no
+// console. Its output is marked as comments, like the log lines.
+func (a *App) newShell(readings *types.Readings, sensors *scheduler.Task) *shell.Shell {
+	if a.console == nil {
+		return nil
+	}
+	out := &commentWriter{w: a.console}
+	return shell.New(a.console, out, a.commands(readings, sensors))
+}
+
+func (a *App) commands(readings *types.Readings, sensors *scheduler.Task) []shell.Command {
+	return []shell.Command{
+		{
+			Name: "status",
+			Help: "show time, latest readings and sensor health",
+			Run: func(w io.Writer, args []string) error {
+				return a.printStatus(w, readings)
+			},
+		},
+		{
+			Name:    "history",
+			Usage:   "<quantity> [count]",
+			Help:    "print the most recent stored values, oldest first",
+			MinArgs: 1,
+			MaxArgs: 2,
+			Run: func(w io.Writer, args []string) error {
+				return printHistory(w, readings, args)
+			},
+		},
+		{
+			Name:    "set",
+			Usage:   "interval <duration> | mode periodic|low-power|single-shot|power-down | telemetry off|csv|jsonl|influx | zone <tz> | carousel on|off",
+			Help:    "set the sensor interval or acquisition mode, telemetry format, POSIX time zone or screen carousel",
+			MinArgs: 2,
+			MaxArgs: 2,
+			Run: func(w io.Writer, args []string) error {
+				switch args[0] {
+				case "interval":
+					return a.setSensorInterval(w, readings, sensors, args[1])
+				case "mode":
+					m, ok := sensor.ParseMode(args[1])
+					if !ok {
+						return shell.ErrUsage
+					}
+					return a.setSensorMode(m)
+				case "zone":
+					if err := a.setTimeZone(args[1]); err != nil {
+						return err
+					}
+					a.config.TimeZone = args[1]
+					a.configChanged()
+					invalidateTime(readings)
+					return nil
+				case "carousel":
+					if args[1] != "on" && args[1] != "off" {
+						return shell.ErrUsage
+					}
+					a.setCarousel(args[1] == "on")
+					return nil
+				case "telemetry":
+					if err := a.setTelemetryFormat(args[1]); err != nil {
+						return err
+					}
+					a.config.Telemetry.Format = args[1]
+					a.configChanged()
+					return nil
+				}
+				return shell.ErrUsage
+			},
+		},
+		{
+			Name:    "telemetry",
+			Usage:   "[header]",
+			Help:    "show the telemetry format or write the CSV header again",
+			MaxArgs: 1,
+			Run: func(w io.Writer, args []string) error {
+				if len(args) == 0 {
+					fmt.Fprintln(w, a.config.Telemetry.Format)
+					return nil
+				}
+				if args[0] != "header" {
+					return shell.ErrUsage
+				}
+				return a.telemetry.WriteHeader()
+			},
+		},
+		{
+			Name:    "screen",
+			Usage:   "next|prev|<index>",
+			Help:    "switch the displayed screen",
+			MinArgs: 1,
+			MaxArgs: 1,
+			Run: func(w io.Writer, args []string) error {
+				return a.setScreen(w, readings, args[0])
+			},
+		},
+		{
+			Name:    "display",
+			Usage:   "[brightness <day> <night> | dim|off <HH:MM> <HH:MM>]",
+			Help:    "show or set the display brightness and night schedule; equal times disable a period",
+			MaxArgs: 3,
+			Run: func(w io.Writer, args []string) error {
+				return a.displayCommand(w, readings, args)
+			},
+		},
+		{
+			Name:    "time",
+			Usage:   "[set YYYY-MM-DDTHH:MM | sync <unix-seconds>]",
+			Help:    "show or set the time; set takes local time, sync is for host tools",
+			MaxArgs: 2,
+			Run: func(w io.Writer, args []string) error {
+				return a.timeCommand(w, readings, args)
+			},
+		},
+		{
+			Name:    "alerts",
+			Usage:   "[snooze [name]]",
+			Help:    "list alert rules and their state, or silence active alerts",
+			MaxArgs: 2,
+			Run: func(w io.Writer, args []string) error {
+				return a.alertsCommand(w, readings, args)
+			},
+		},
+		{
+			Name:    "calibrate",
+			Usage:   "[co2 <ppm> | asc on|off | altitude <m> | pressure <hPa>|off]",
+			Help:    "show the CO2 calibration, force a recalibration to a reference or set self-calibration and compensation",
+			MaxArgs: 2,
+			Run: func(w io.Writer, args []string) error {
+				return a.calibrateCommand(w, readings, args)
+			},
+		},
+		{
+			Name:    "diag",
+			Usage:   "[sensor]",
+			Help:    "print raw sensor values such as the applied compensation",
+			MaxArgs: 1,
+			Run: func(w io.Writer, args []string) error {
+				name := ""
+				if len(args) == 1 {
+					name = args[0]
+				}
+				return a.sensors.Diagnose(w, name)
+			},
+		},
+		{
+			Name: "reboot",
+			Help: "restart the device",
+			Run: func(w io.Writer, args []string) error {
+				if a.reset == nil {
+					return errors.New("reboot not supported")
+				}
+				a.persistConfig()
+				fmt.Fprintln(w, "rebooting")
+				a.reset()
+				return nil
+			},
+		},
+	}
+}
+
+func (a *App) printStatus(w io.Writer, readings *types.Readings) error {
+	if now, err := a.ds3231.ReadTime(); err == nil {
+		fmt.Fprintf(w, "time        %s %s\n", a.localTime(now).Format(time.DateTime), a.zone)
+	} else {
+		fmt.Fprintf(w, "time        error: %v\n", err)
+	}
+
+	raw := readings.Raw
+	for _, q := range types.AllQuantities {
+		if !raw.Valid.Has(q) {
+			continue
+		}
+		fmt.Fprintf(w, "%-11s %s\n", q, formatQuantity(raw, q))
+	}
+
+	if c := readings.Calculated; c.CO2SlopeValid {
+		fmt.Fprintf(w, "co2 slope   %+.0f ppm/h", c.CO2Slope)
+		if c.ThresholdIn > 0 {
+			fmt.Fprintf(w, ", %d ppm in %s", c.NextThreshold, c.ThresholdIn)
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, h := range readings.Sensors {
+		fmt.Fprintf(w, "sensor      %s %s", h.Name, h.State)
+		if h.State != types.SensorOK {
+			fmt.Fprintf(w, " (%d failures): %s", h.Failures, h.LastError)
+		}
+		if id := a.sensors.Identity(h.Name); id != "" {
+			fmt.Fprintf(w, ", %s", id)
+		}
+		fmt.Fprintln(w)
+	}
+
+	index := a.displayManager.CurrentDisplay()
+	fmt.Fprintf(w, "screen      %d %s\n", index, display.MethodRegistry[index].Name)
+	fmt.Fprintf(w, "display     %s\n", a.displayMode)
+	fmt.Fprintf(w, "interval    %s %s\n", a.config.Intervals.Sensors, a.config.SensorMode)
+	fmt.Fprintf(w, "calibrated  %s\n", a.formatCalibration())
+	return nil
+}
+
+func formatQuantity(raw types.RawReadings, q types.Quantity) string {
+	switch q {
+	case types.CO2:
+		return fmt.Sprintf("%d ppm", raw.CO2)
+	case types.Temperature:
+		return fmt.Sprintf("%.2f C", raw.Temperature)
+	case types.Humidity:
+		return fmt.Sprintf("%.2f %%", raw.Humidity)
+	case types.TVOC:
+		return fmt.Sprintf("%d ppb", raw.TVOC)
+	case types.ECO2:
+		return fmt.Sprintf("%d ppm", raw.ECO2)
+	case types.AQI:
+		return strconv.Itoa(int(raw.AQI) + 1)
+	case types.Pressure:
+		return fmt.Sprintf("%.1f hPa", raw.Pressure)
+	}
+	return ""
+}
+
+func printHistory(w io.Writer, readings *types.Readings, args []string) error {
+	q, ok := types.ParseQuantity(args[0])
+	history := readings.History.Of(q)
+	if !ok || history == nil {
+		return fmt.Errorf("no history for %q", args[0])
+	}
+
+	count := 10
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid count %q", args[1])
+		}
+		count = n
+	}
+
+	values := history.Contiguous()
+	if len(values) > count {
+		values = values[len(values)-count:]
+	}
+	if len(values) == 0 {
+		fmt.Fprintln(w, "no values yet")
+		return nil
+	}
+
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = strconv.Itoa(int(v))
+	}
+	fmt.Fprintln(w, strings.Join(strs, " "))
+	return nil
+}
+
+func (a *App) setSensorInterval(
+	w io.Writer,
+	readings *types.Readings,
+	sensors *scheduler.Task,
+	arg string,
+) error {
+	d, err := time.ParseDuration(arg)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", arg)
+	}
+	if err := a.applySensorInterval(readings, sensors, d); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "interval set to %s\n", d)
+	return nil
+}
+
+// applySensorInterval validates and stores the sensor interval. It takes
+// effect right away unless the startup period is still running.
+func (a *App) applySensorInterval(readings *types.Readings, sensors *scheduler.Task, d time.Duration) error {
+	if d < minSensorInterval || d > maxSensorInterval {
+		return fmt.Errorf("interval must be between %s and %s", minSensorInterval, maxSensorInterval)
+	}
+
+	a.config.Intervals.Sensors = d
+	if a.startupDone(readings) {
+		sensors.SetPeriod(d)
+	}
+	a.configChanged()
+	return nil
+}
+
+func (a *App) setScreen(w io.Writer, readings *types.Readings, arg string) error {
+	switch arg {
+	case "next":
+		a.stepScreen(1)
+	case "prev":
+		a.stepScreen(-1)
+	default:
+		index, err := strconv.Atoi(arg)
+		if err != nil || index < 0 || index >= len(display.MethodRegistry) {
+			return fmt.Errorf("screen must be next, prev or 0-%d", len(display.MethodRegistry)-1)
+		}
+		a.selectScreen(index)
+	}
+
+	readings.IsDrawen = false
+	index := a.displayManager.CurrentDisplay()
+	fmt.Fprintf(w, "screen %d %s\n", index, display.MethodRegistry[index].Name)
+	return nil
+}
+
+func (a *App) displayCommand(w io.Writer, readings *types.Readings, args []string) error {
+	n := &a.config.Night
+	if len(args) == 0 {
+		fmt.Fprintf(w, "%s, brightness day %d night %d, dim %s, off %s, wake %s\n",
+			a.displayMode, a.config.Display.Brightness, n.Brightness, n.Dim, n.Off, n.Wake)
+		return nil
+	}
+	if len(args) != 3 {
+		return shell.ErrUsage
+	}
+
+	switch args[0] {
+	case "brightness":
+		day, err1 := strconv.ParseUint(args[1], 10, 8)
+		night, err2 := strconv.ParseUint(args[2], 10, 8)
+		if err1 != nil || err2 != nil {
+			return errors.New("brightness must be between 0 and 255")
+		}
+		a.config.Display.Brightness, n.Brightness = uint8(day), uint8(night)
+	case "dim", "off":
+		from, err := ParseTimeOfDay(args[1])
+		if err != nil {
+			return err
+		}
+		until, err := ParseTimeOfDay(args[2])
+		if err != nil {
+			return err
+		}
+		if args[0] == "dim" {
+			n.Dim = Period{From: from, Until: until}
+		} else {
+			n.Off = Period{From: from, Until: until}
+		}
+	default:
+		return shell.ErrUsage
+	}
+
+	a.configChanged()
+	a.setDisplayMode(readings, a.displayMode)
+	a.updateDisplayMode(readings)
+	fmt.Fprintf(w, "display %s\n", a.displayMode)
+	return nil
+}
+
+func (a *App) timeCommand(w io.Writer, readings *types.Readings, args []string) error {
+	if len(args) == 0 {
+		now, err := a.ds3231.ReadTime()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s %s (%s UTC), source %s\n",
+			a.localTime(now).Format(time.DateTime), a.zone,
+			now.Format(time.DateTime), a.timeSource)
+		return nil
+	}
+	if len(args) != 2 {
+		return shell.ErrUsage
+	}
+
+	var (
+		t   time.Time
+		src TimeSource
+	)
+	switch args[0] {
+	case "set":
+		wall, err := time.Parse(timeSetLayout, args[1])
+		if err != nil {
+			return fmt.Errorf("invalid time %q, want YYYY-MM-DDTHH:MM", args[1])
+		}
+		t = a.zone.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0)
+		src = TimeConsole
+	case "sync":
+		sec, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid unix time %q", args[1])
+		}
+		t, src = time.Unix(sec, 0).UTC(), TimeExternal
+	default:
+		return shell.ErrUsage
+	}
+
+	if err := a.SetTime(t, src); err != nil {
+		return err
+	}
+	invalidateTime(readings)
+	fmt.Fprintf(w, "time set to %s UTC\n", t.Format(time.DateTime))
+	return nil
+}
+
+func (a *App) alertsCommand(w io.Writer, readings *types.Readings, args []string) error {
+	if len(args) == 0 {
+		now := a.clock.Now()
+		for _, al := range a.alerts.Alerts() {
+			r := al.Rule
+			fmt.Fprintf(w, "%-14s %-8s %-11s enter %g exit %g for %s: %s",
+				r.Name, r.Severity, r.Metric, r.Enter, r.Exit, r.For, al.State)
+			if al.State == alert.Active {
+				fmt.Fprintf(w, " since %s, value %.1f", al.Since.Format(time.TimeOnly), al.Value)
+			}
+			if al.Snoozed(now) {
+				fmt.Fprintf(w, ", snoozed until %s", al.SnoozedUntil.Format(time.TimeOnly))
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+	if args[0] != "snooze" {
+		return shell.ErrUsage
+	}
+
+	name := ""
+	if len(args) == 2 {
+		name = args[1]
+		if a.alerts.Alert(name) == nil {
+			return fmt.Errorf("unknown alert %q", name)
+		}
+	}
+	n := a.snoozeAlerts(readings, name)
+	fmt.Fprintf(w, "%d alerts snoozed for %s\n", n, a.config.Alerts.Snooze)
+	return nil
+}
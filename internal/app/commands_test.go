@@ -0,0 +1,192 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/store"
+)
+
+func TestShellCommands(t *testing.T) {
+	a, board := newTestApp(t)
+	console := &hal.FakeConsole{}
+	a.console = console
+	a.setTelemetryFormat("csv")
+	a.store = store.NewMemStore()
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+	s.RunPending()
+
+	run := func(line string) string {
+		console.Out.Reset()
+		console.In.WriteString(line + "\n")
+		board.Clock.Sleep(a.config.Intervals.Input)
+		s.RunPending()
+		// Shell output is marked as comments; the rest are records.
+		var out strings.Builder
+		for l := range strings.Lines(console.Out.String()) {
+			if c, ok := strings.CutPrefix(l, "# "); ok {
+				out.WriteString(c)
+			}
+		}
+		return out.String()
+	}
+
+	if out := run("status"); !strings.Contains(out, "co2         800 ppm") ||
+		!strings.Contains(out, "sensor      scd4x ok") ||
+		!strings.Contains(out, "sensor      ens160 ok, firmware 5.4.6") {
+		t.Errorf("Unexpected status output:\n%s", out)
+	}
+
+	if out := run("history co2"); strings.TrimSpace(out) != "800" {
+		t.Errorf("Expected CO2 history \"800\", got %q", out)
+	}
+
+	if out := run("diag"); !strings.Contains(out, "compensation 24.99 C 50.0 %") ||
+		!strings.Contains(out, "hp0 131072 ohm, hp3 1048576 ohm") {
+		t.Errorf("Unexpected diagnostics:\n%s", out)
+	}
+	if out := run("diag scd4x"); !strings.Contains(out, "no sensor with diagnostics") {
+		t.Errorf("Expected no SCD4x diagnostics, got %q", out)
+	}
+
+	if out := run("set interval 1s"); !strings.Contains(out, "error: interval must be") {
+		t.Errorf("Expected interval validation error, got %q", out)
+	}
+	run("set interval 30s")
+	if a.config.Intervals.Sensors != 30*time.Second || !a.configDirty {
+		t.Errorf("Expected 30s interval marked for saving, got %v", a.config.Intervals.Sensors)
+	}
+
+	if out := run("telemetry"); out != "csv\n" {
+		t.Errorf("Expected the telemetry format, got %q", out)
+	}
+	run("telemetry header")
+	if !strings.HasPrefix(console.Out.String(), "device,time,time_trusted,") {
+		t.Errorf("Expected the CSV header, got %q", console.Out.String())
+	}
+
+	run("screen next")
+	if a.displayManager.CurrentDisplay() != 1 {
+		t.Errorf("Expected screen 1, got %d", a.displayManager.CurrentDisplay())
+	}
+
+	if out := run("time set 2026-10-16T12:00"); !strings.Contains(out, "2026-10-16 12:00:00") {
+		t.Errorf("Expected time confirmation, got %q", out)
+	}
+	if readings.Time.Minute != -1 {
+		t.Errorf("Expected RTC minute to be reset, got %d", readings.Time.Minute)
+	}
+	if out := run("time set yesterday"); !strings.Contains(out, "invalid time") {
+		t.Errorf("Expected invalid time error, got %q", out)
+	}
+
+	if out := run("calibrate tvoc 10"); !strings.Contains(out, "cannot be calibrated") {
+		t.Errorf("Expected calibration error, got %q", out)
+	}
+
+	if out := run("reboot"); !strings.Contains(out, "reboot not supported") {
+		t.Errorf("Expected reboot error without reset, got %q", out)
+	}
+	rebooted := false
+	a.reset = func() { rebooted = true }
+	run("reboot")
+	if !rebooted {
+		t.Error("Expected reboot to reset the board")
+	}
+}
+
+func TestShellCalibrateCO2(t *testing.T) {
+	a, board := newTestApp(t)
+
+	var frc []byte
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		if len(w) == 5 && w[0] == 0x36 && w[1] == 0x2F {
+			frc = append([]byte(nil), w[2:4]...)
+		}
+		if len(w) == 0 && len(r) == 3 {
+			copy(r, []byte{0x80, 0x00, 0xA2}) // correction 0 ppm
+		}
+		return nil
+	})
+
+	console := &hal.FakeConsole{}
+	a.console = console
+	a.store = store.NewMemStore()
+	a.timeSource = TimeConsole
+	sh := a.newShell(a.newReadings(), nil)
+	sh.Exec("calibrate co2 420")
+
+	if !strings.Contains(console.Out.String(), "co2 calibrated to 420") {
+		t.Errorf("Unexpected output %q", console.Out.String())
+	}
+	if len(frc) != 2 || int(frc[0])<<8|int(frc[1]) != 420 {
+		t.Errorf("Expected forced recalibration to 420 ppm, got % X", frc)
+	}
+	if cal := a.config.Calibration; cal.Last.IsZero() || cal.LastReference != 420 || !a.configDirty {
+		t.Errorf("Expected the calibration to be recorded, got %+v", cal)
+	}
+
+	// Without a trusted time the date is not known.
+	a.timeSource = TimeBuild
+	console.Out.Reset()
+	sh.Exec("calibrate co2 450")
+	sh.Exec("calibrate")
+	if cal := a.config.Calibration; !cal.Last.IsZero() || cal.LastReference != 450 {
+		t.Errorf("Expected an unknown calibration date, got %+v", cal)
+	}
+	if out := console.Out.String(); !strings.Contains(out, "last        unknown date to 450 ppm") {
+		t.Errorf("Expected the unknown date in %q", out)
+	}
+}
+
+func TestShellCalibrationSettings(t *testing.T) {
+	a, board := newTestApp(t)
+
+	// Commands with an argument, in the order sent.
+	var cmds []string
+	board.I2C.(*hal.FakeI2C).Attach(scd4xAddr, func(w, r []byte) error {
+		switch len(w) {
+		case 2:
+			cmds = append(cmds, fmt.Sprintf("%02X%02X", w[0], w[1]))
+		case 5:
+			cmds = append(cmds, fmt.Sprintf("%02X%02X=%d", w[0], w[1], int(w[2])<<8|int(w[3])))
+		}
+		return nil
+	})
+
+	console := &hal.FakeConsole{}
+	a.console = console
+	a.store = store.NewMemStore()
+	sh := a.newShell(a.newReadings(), nil)
+
+	sh.Exec("calibrate asc off")
+	sh.Exec("calibrate altitude 520")
+	sh.Exec("calibrate pressure 950")
+	want := []string{
+		"3F86", "2416=0", "3615", "21B1", // stop, ASC off, persist, start
+		"3F86", "2427=520", "3615", "21B1",
+		"E000=950", // while measuring
+	}
+	if !slices.Equal(cmds, want) {
+		t.Errorf("Expected commands %v, got %v", want, cmds)
+	}
+
+	cal := a.config.Calibration
+	if cal.AutoCalibration || cal.Altitude != 520 || cal.Pressure != 950 || !a.configDirty {
+		t.Errorf("Expected the settings to be saved, got %+v", cal)
+	}
+
+	console.Out.Reset()
+	sh.Exec("calibrate pressure 500")
+	sh.Exec("calibrate")
+	for _, s := range []string{"out of range 700-1200", "asc         off", "pressure    950 hPa", "last        never"} {
+		if !strings.Contains(console.Out.String(), s) {
+			t.Errorf("Expected %q in %q", s, console.Out.String())
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !tinygo
+
+package app
+
+import (
+	"pico_co2/internal/display"
+
+	"tinygo.org/x/drivers"
+)
+
+// initDisplay renders into an in-memory display when running on the host.
+func (c Config) initDisplay(bus drivers.I2C) (display.Renderer, error) {
+	return display.NewVirtualDisplay(c.Display.Width, c.Display.Height), nil
+}
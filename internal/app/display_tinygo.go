@@ -0,0 +1,20 @@
+//go:build tinygo
+
+package app
+
+import (
+	"pico_co2/internal/display"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/ssd1306"
+)
+
+func (c Config) initDisplay(bus drivers.I2C) (display.Renderer, error) {
+	disp := ssd1306.NewI2C(bus)
+	disp.Configure(ssd1306.Config{
+		Width:   c.Display.Width,
+		Height:  c.Display.Height,
+		Address: c.Display.Address,
+	})
+	return display.NewSSD1306Adapter(&disp), nil
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"pico_co2/internal/telemetry"
+)
+
+// logOutput receives the log lines. On the board stderr is the serial
+// console, shared with the telemetry records.
+var logOutput io.Writer = os.Stderr
+
+// logln writes a log line like println, marked as a comment so tools
+// reading the telemetry records skip it.
+func logln(args ...any) {
+	fmt.Fprintln(logOutput, append([]any{telemetry.CommentPrefix}, args...)...)
+}
+
+// commentWriter starts every line written to w with the comment prefix,
+// for shell output on the console.
+type commentWriter struct {
+	w       io.Writer
+	midLine bool
+}
+
+func (c *commentWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		if !c.midLine {
+			if _, err := io.WriteString(c.w, telemetry.CommentPrefix+" "); err != nil {
+				return n, err
+			}
+			c.midLine = true
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			c.midLine = false
+		}
+		m, err := c.w.Write(line)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[len(line):]
+	}
+	return n, nil
+}
@@ -0,0 +1,300 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/button"
+	"pico_co2/internal/display"
+	"pico_co2/internal/menu"
+	"pico_co2/internal/sensor"
+	"pico_co2/internal/types"
+	"pico_co2/pkg/scheduler"
+)
+
+// menuTimeout closes the settings menu after the last button gesture.
+const menuTimeout = time.Minute
+
+// defaultCalibrationReference is fresh outdoor air.
+const defaultCalibrationReference = 420
+
+// metricRanges are the threshold ranges offered in the menu.
+var metricRanges = map[alert.Metric]struct{ min, max, step int }{
+	alert.MetricCO2:         {400, 5000, 50},
+	alert.MetricCO2Rate:     {50, 2000, 50},
+	alert.MetricHeatIndex:   {20, 50, 1},
+	alert.MetricTemperature: {0, 50, 1},
+	alert.MetricHumidity:    {5, 95, 1},
+	alert.MetricTVOC:        {100, 5000, 50},
+	alert.MetricECO2:        {400, 5000, 50},
+}
+
+// newMenu builds the settings menu. Every entry validates its value,
+// applies it and marks the config to be saved.
+func (a *App) newMenu(readings *types.Readings, sensors *scheduler.Task) *menu.Menu {
+	night := &a.config.Night
+	return menu.New("Settings", []menu.Item{
+		{Label: "Alerts", Items: a.alertItems()},
+		{
+			Label: "Interval",
+			Get:   func() int { return int(a.config.Intervals.Sensors / time.Second) },
+			Set: func(v int) error {
+				return a.applySensorInterval(readings, sensors, time.Duration(v)*time.Second)
+			},
+			Min:  int(minSensorInterval / time.Second),
+			Max:  int(maxSensorInterval / time.Second),
+			Step: 5,
+			Unit: "s",
+		},
+		menu.Choice("Mode", sensor.ModeStrings[:],
+			func() int { return int(a.config.SensorMode) },
+			func(v int) error { return a.setSensorMode(sensor.Mode(v)) },
+		),
+		{Label: "Display", Items: []menu.Item{
+			a.brightnessItem("Day bright", &a.config.Display.Brightness, readings),
+			a.brightnessItem("Night bright", &night.Brightness, readings),
+			a.timeOfDayItem("Dim from", &night.Dim.From, readings),
+			a.timeOfDayItem("Dim until", &night.Dim.Until, readings),
+			a.timeOfDayItem("Off from", &night.Off.From, readings),
+			a.timeOfDayItem("Off until", &night.Off.Until, readings),
+			{
+				Label: "Wake",
+				Get:   func() int { return int(night.Wake / time.Second) },
+				Set: func(v int) error {
+					night.Wake = time.Duration(v) * time.Second
+					a.configChanged()
+					return nil
+				},
+				Min:  5,
+				Max:  300,
+				Step: 5,
+				Unit: "s",
+			},
+			menu.Toggle("Carousel",
+				func() bool { return a.config.Carousel.Enabled },
+				func(on bool) error {
+					a.setCarousel(on)
+					return nil
+				},
+			),
+		}},
+		{Label: "Screens", Items: a.screenItems()},
+		menu.Choice("Temp unit", types.TemperatureUnitStrings[:],
+			func() int { return int(a.config.Units.Temperature) },
+			func(v int) error {
+				a.config.Units.Temperature = types.TemperatureUnit(v)
+				readings.TempUnit = a.config.Units.Temperature
+				a.configChanged()
+				return nil
+			},
+		),
+		{Label: "Time", Items: a.timeItems(readings)},
+		{Label: "Calibrate", Items: []menu.Item{
+			{
+				Label: "CO2 to",
+				Get:   func() int { return a.calibrationReference },
+				Set: func(v int) error {
+					a.calibrationReference = v
+					return a.calibrateQuantity(readings, types.CO2, float32(v))
+				},
+				Min:  400,
+				Max:  2000,
+				Step: 10,
+				Unit: "ppm",
+			},
+			menu.Toggle("Auto calib",
+				func() bool { return a.config.Calibration.AutoCalibration },
+				a.setAutoCalibration,
+			),
+			{
+				Label: "Altitude",
+				Get:   func() int { return int(a.config.Calibration.Altitude) },
+				Set:   func(v int) error { return a.setAltitude(uint16(v)) },
+				Max:   3000,
+				Step:  50,
+				Unit:  "m",
+			},
+		}},
+	})
+}
+
+// alertItems edits the Enter threshold of every alert rule. Exit moves
+// along, keeping the hysteresis.
+func (a *App) alertItems() []menu.Item {
+	items := make([]menu.Item, 0, len(a.config.Alerts.Rules))
+	for i, r := range a.config.Alerts.Rules {
+		rng, ok := metricRanges[r.Metric]
+		if !ok {
+			continue
+		}
+		items = append(items, menu.Item{
+			Label: r.Name,
+			Get:   func() int { return int(a.config.Alerts.Rules[i].Enter) },
+			Set: func(v int) error {
+				rule := &a.config.Alerts.Rules[i]
+				rule.Exit += float32(v) - rule.Enter
+				rule.Enter = float32(v)
+				a.alerts.SetRules(a.config.Alerts.Rules)
+				a.configChanged()
+				return nil
+			},
+			Min:  rng.min,
+			Max:  rng.max,
+			Step: rng.step,
+		})
+	}
+	return items
+}
+
+func (a *App) brightnessItem(label string, level *uint8, readings *types.Readings) menu.Item {
+	return menu.Item{
+		Label: label,
+		Get:   func() int { return int(*level) },
+		Set: func(v int) error {
+			*level = uint8(v)
+			a.setDisplayMode(readings, a.displayMode)
+			a.configChanged()
+			return nil
+		},
+		Max:       255,
+		Step:      5,
+		Immediate: true,
+	}
+}
+
+func (a *App) timeOfDayItem(label string, t *TimeOfDay, readings *types.Readings) menu.Item {
+	return menu.Item{
+		Label: label,
+		Get:   func() int { return int(*t) },
+		Set: func(v int) error {
+			*t = TimeOfDay(v)
+			a.updateDisplayMode(readings)
+			a.configChanged()
+			return nil
+		},
+		Max:    minutesPerDay - 15,
+		Step:   15,
+		Format: func(v int) string { return TimeOfDay(v).String() },
+		Wrap:   true,
+	}
+}
+
+// screenItems shows or hides every screen for the buttons.
+func (a *App) screenItems() []menu.Item {
+	items := make([]menu.Item, len(display.MethodRegistry))
+	for i, m := range display.MethodRegistry {
+		items[i] = menu.Toggle(strings.TrimPrefix(m.Name, "Render"),
+			func() bool { return !a.screenHidden(i) },
+			func(on bool) error { return a.setScreenHidden(i, !on) },
+		)
+	}
+	return items
+}
+
+// timeItems set one field of the local date and time each. The RTC keeps
+// counting while a field is edited, so the fields are read again on save.
+func (a *App) timeItems(readings *types.Readings) []menu.Item {
+	field := func(label string, lo, hi int, get func(time.Time) int, set func(t *[5]int, v int)) menu.Item {
+		return menu.Item{
+			Label: label,
+			Get:   func() int { return get(a.localTime(a.rtcNow(readings))) },
+			Set: func(v int) error {
+				now := a.localTime(a.rtcNow(readings))
+				f := [5]int{now.Year(), int(now.Month()), now.Day(), now.Hour(), now.Minute()}
+				set(&f, v)
+				return a.setLocalTime(readings, f)
+			},
+			Min:  lo,
+			Max:  hi,
+			Step: 1,
+			Wrap: true,
+		}
+	}
+	return []menu.Item{
+		field("Year", minValidYear, maxValidYear, time.Time.Year, func(f *[5]int, v int) { f[0] = v }),
+		field("Month", 1, 12, func(t time.Time) int { return int(t.Month()) }, func(f *[5]int, v int) { f[1] = v }),
+		field("Day", 1, 31, time.Time.Day, func(f *[5]int, v int) { f[2] = v }),
+		field("Hour", 0, 23, time.Time.Hour, func(f *[5]int, v int) { f[3] = v }),
+		field("Minute", 0, 59, time.Time.Minute, func(f *[5]int, v int) { f[4] = v }),
+	}
+}
+
+// setLocalTime sets the RTC from local year, month, day, hour and minute.
+func (a *App) setLocalTime(readings *types.Readings, f [5]int) error {
+	t := a.zone.Date(f[0], time.Month(f[1]), f[2], f[3], f[4], 0)
+	if local := a.localTime(t); local.Day() != f[2] || local.Month() != time.Month(f[1]) {
+		return fmt.Errorf("no day %d in month %d", f[2], f[1])
+	}
+	if err := a.SetTime(t, TimeConsole); err != nil {
+		return err
+	}
+	invalidateTime(readings)
+	return nil
+}
+
+func (a *App) screenHidden(index int) bool {
+	return slices.Contains(a.config.HiddenScreens, display.MethodRegistry[index].Name)
+}
+
+// setScreenHidden hides or shows a screen for the buttons. At least one
+// screen stays visible.
+func (a *App) setScreenHidden(index int, hidden bool) error {
+	name := display.MethodRegistry[index].Name
+	if hidden == a.screenHidden(index) {
+		return nil
+	}
+	if !hidden {
+		a.config.HiddenScreens = slices.DeleteFunc(a.config.HiddenScreens, func(n string) bool { return n == name })
+		a.configChanged()
+		return nil
+	}
+	if len(a.config.HiddenScreens)+1 >= len(display.MethodRegistry) {
+		return errors.New("keep one screen")
+	}
+	a.config.HiddenScreens = append(a.config.HiddenScreens, name)
+	a.configChanged()
+	return nil
+}
+
+// handleMenu passes a gesture to the settings menu. A long press or a
+// chord opens the menu. It reports whether the menu took the gesture.
+func (a *App) handleMenu(readings *types.Readings, g button.GestureEvent) bool {
+	if a.menu == nil {
+		return false
+	}
+
+	switch {
+	case a.menu.IsOpen():
+		a.menu.Handle(g)
+	case g.Gesture == button.LongPress || g.Gesture == button.Chord:
+		a.menu.Open()
+	default:
+		return false
+	}
+
+	// The rest of the closing press must not switch screens.
+	if !a.menu.IsOpen() {
+		a.gestures.Suppress()
+	}
+	a.menuUntil = a.clock.Now().Add(menuTimeout)
+	a.menuTask.TriggerAt(a.menuUntil)
+	readings.IsDrawen = false
+	return true
+}
+
+// closeIdleMenu closes the menu once it was not used for menuTimeout.
+func (a *App) closeIdleMenu(readings *types.Readings) {
+	if !a.menu.IsOpen() {
+		return
+	}
+	if a.clock.Now().Before(a.menuUntil) {
+		a.menuTask.TriggerAt(a.menuUntil)
+		return
+	}
+	a.menu.Close()
+	readings.IsDrawen = false
+}
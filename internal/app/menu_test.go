@@ -0,0 +1,81 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/display"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/store"
+)
+
+func TestMenuChangesInterval(t *testing.T) {
+	a, board := newTestApp(t)
+	a.store = store.NewMemStore()
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+	b1, b2 := board.Button1.(*hal.FakePin), board.Button2.(*hal.FakePin)
+
+	run := func(d time.Duration) {
+		end := board.Clock.Now().Add(d)
+		for board.Clock.Now().Before(end) {
+			board.Clock.Sleep(s.RunPending())
+		}
+	}
+	tap := func(p *hal.FakePin) {
+		p.Press()
+		run(100 * time.Millisecond)
+	}
+
+	// A long press opens the menu without switching screens.
+	b2.Set(true)
+	run(time.Second)
+	b2.Set(false)
+	run(100 * time.Millisecond)
+	if !a.menu.IsOpen() || a.displayManager.CurrentDisplay() != 0 {
+		t.Fatalf("Expected the menu open on screen 0, got %v %d", a.menu.IsOpen(), a.displayManager.CurrentDisplay())
+	}
+
+	tap(b1) // Interval
+	tap(b2) // edit
+	tap(b2) // +5s
+	b1.Set(true)
+	b2.Set(true)
+	run(100 * time.Millisecond)
+	b1.Set(false)
+	b2.Set(false)
+	run(100 * time.Millisecond)
+
+	if a.config.Intervals.Sensors != 65*time.Second || !a.configDirty {
+		t.Errorf("Expected a 65s interval marked for saving, got %s", a.config.Intervals.Sensors)
+	}
+	if v := a.menu.View(); v.Label != "Interval" || v.Message != "saved" {
+		t.Errorf("Expected the saved interval, got %+v", v)
+	}
+
+	run(menuTimeout)
+	if a.menu.IsOpen() {
+		t.Error("Expected the idle menu to close")
+	}
+}
+
+func TestHiddenScreensAreSkipped(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	if err := a.setScreenHidden(1, true); err != nil {
+		t.Fatal(err)
+	}
+	a.stepScreen(1)
+	if got := a.displayManager.CurrentDisplay(); got != 2 {
+		t.Errorf("Expected screen 2 after skipping screen 1, got %d", got)
+	}
+
+	for i := range display.MethodRegistry {
+		if i != 2 {
+			a.setScreenHidden(i, true)
+		}
+	}
+	if err := a.setScreenHidden(2, true); err == nil {
+		t.Error("Expected the last visible screen to stay")
+	}
+}
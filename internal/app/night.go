@@ -0,0 +1,159 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"pico_co2/internal/types"
+)
+
+// TimeOfDay is a local wall clock time in minutes after midnight. It is
+// stored and entered as "HH:MM".
+type TimeOfDay uint16
+
+const minutesPerDay = 24 * 60
+
+// ParseTimeOfDay parses "HH:MM".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return TimeOfDay(t.Hour()*60 + t.Minute()), nil
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t/60, t%60)
+}
+
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	v, err := ParseTimeOfDay(string(text))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Period is a daily time range that may wrap around midnight. A period
+// whose ends are equal is disabled.
+type Period struct {
+	From  TimeOfDay
+	Until TimeOfDay
+}
+
+// Contains reports whether t lies in [From, Until).
+func (p Period) Contains(t TimeOfDay) bool {
+	if p.From <= p.Until {
+		return t >= p.From && t < p.Until
+	}
+	return t >= p.From || t < p.Until
+}
+
+func (p Period) String() string {
+	if p.From == p.Until {
+		return "never"
+	}
+	return p.From.String() + "-" + p.Until.String()
+}
+
+// displayMode is the brightness state of the display.
+type displayMode uint8
+
+const (
+	displayDay   displayMode = iota // Display.Brightness
+	displayNight                    // Night.Brightness
+	displayOff                      // panel off, not rendered
+)
+
+var displayModeStrings = [...]string{
+	"day",
+	"night",
+	"off",
+}
+
+func (m displayMode) String() string {
+	if m > displayOff {
+		return "unknown"
+	}
+	return displayModeStrings[m]
+}
+
+// scheduledMode returns the display mode for the current local time. The
+// schedule only applies once the RTC time can be trusted.
+func (a *App) scheduledMode(readings *types.Readings) displayMode {
+	t := readings.Time
+	if !t.Trusted || t.Minute < 0 {
+		return displayDay
+	}
+
+	now := TimeOfDay((t.Hour*60 + t.Minute) % minutesPerDay)
+	switch {
+	case a.config.Night.Off.Contains(now):
+		return displayOff
+	case a.config.Night.Dim.Contains(now):
+		return displayNight
+	}
+	return displayDay
+}
+
+// updateDisplayMode follows the schedule. While the display was woken by a
+// button press it is one step brighter: on at night brightness during the
+// off period and at day brightness during the dim period.
+func (a *App) updateDisplayMode(readings *types.Readings) {
+	mode := a.scheduledMode(readings)
+	if mode != displayDay && a.clock.Now().Before(a.wakeUntil) {
+		mode--
+	}
+	if mode != a.displayMode {
+		logln("display", a.displayMode.String(), "->", mode.String())
+		a.setDisplayMode(readings, mode)
+	}
+}
+
+// setDisplayMode applies the brightness and power of mode to the panel.
+func (a *App) setDisplayMode(readings *types.Readings, mode displayMode) {
+	r := a.displayManager.renderer
+	switch mode {
+	case displayDay:
+		r.SetBrightness(a.config.Display.Brightness)
+	case displayNight:
+		r.SetBrightness(a.config.Night.Brightness)
+	}
+	r.SetPower(mode != displayOff)
+
+	a.displayMode = mode
+	if readings != nil && mode != displayOff {
+		readings.IsDrawen = false
+	}
+}
+
+// wakeDisplay brightens the display for Night.Wake after a button press
+// during the dim or off period. It reports whether the display was off, in
+// which case the press only wakes it.
+func (a *App) wakeDisplay(readings *types.Readings) bool {
+	if a.scheduledMode(readings) == displayDay {
+		return false
+	}
+
+	wasOff := a.displayMode == displayOff
+	a.wakeUntil = a.clock.Now().Add(a.config.Night.Wake)
+	a.updateDisplayMode(readings)
+	if a.wakeTask != nil {
+		a.wakeTask.TriggerAt(a.wakeUntil)
+	}
+	return wasOff
+}
+
+// endWake dims the display again once the wake period is over. A
+// press during the wake period extends it, so the task may run early.
+func (a *App) endWake(readings *types.Readings) {
+	a.updateDisplayMode(readings)
+	if a.clock.Now().Before(a.wakeUntil) {
+		a.wakeTask.TriggerAt(a.wakeUntil)
+	}
+}
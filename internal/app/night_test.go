@@ -0,0 +1,117 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/display"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/store"
+)
+
+func TestPeriodContains(t *testing.T) {
+	tests := []struct {
+		p    Period
+		t    string
+		want bool
+	}{
+		{Period{From: 21 * 60, Until: 7 * 60}, "23:59", true},
+		{Period{From: 21 * 60, Until: 7 * 60}, "00:00", true},
+		{Period{From: 21 * 60, Until: 7 * 60}, "07:00", false},
+		{Period{From: 21 * 60, Until: 7 * 60}, "20:59", false},
+		{Period{From: 12 * 60, Until: 13 * 60}, "12:30", true},
+		{Period{From: 12 * 60, Until: 13 * 60}, "13:00", false},
+		{Period{}, "00:00", false},
+	}
+	for _, tt := range tests {
+		at, err := ParseTimeOfDay(tt.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.p.Contains(at); got != tt.want {
+			t.Errorf("%s contains %s: expected %v, got %v", tt.p, tt.t, tt.want, got)
+		}
+	}
+}
+
+func TestNightSchedule(t *testing.T) {
+	a, board := newTestApp(t)
+	vd := a.displayManager.renderer.(*display.VirtualDisplay)
+	// The fake RTC starts at 14:23.
+	a.config.Display.Brightness = 0x7F
+	a.config.Night.Dim = Period{From: 14 * 60, Until: 16 * 60}
+	a.config.Night.Off = Period{From: 14*60 + 30, Until: 15 * 60}
+	a.timeSource = TimeConsole
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+
+	run := func(d time.Duration) {
+		end := board.Clock.Now().Add(d)
+		for board.Clock.Now().Before(end) {
+			board.Clock.Sleep(s.RunPending())
+		}
+	}
+
+	run(time.Second)
+	if !vd.PowerOn() || vd.Brightness() != a.config.Night.Brightness {
+		t.Errorf("Expected night brightness at 14:23, got %d (on %v)", vd.Brightness(), vd.PowerOn())
+	}
+
+	// A press while dimmed restores day brightness and acts as usual.
+	board.Button2.(*hal.FakePin).Press()
+	run(time.Second)
+	if vd.Brightness() != a.config.Display.Brightness {
+		t.Errorf("Expected day brightness after a press while dimmed, got %d", vd.Brightness())
+	}
+	screen := a.displayManager.CurrentDisplay()
+	if screen != 1 {
+		t.Errorf("Expected the press to change the screen, got %d", screen)
+	}
+	run(a.config.Night.Wake)
+	if vd.Brightness() != a.config.Night.Brightness {
+		t.Errorf("Expected night brightness after the wake period, got %d", vd.Brightness())
+	}
+
+	run(7 * time.Minute)
+	if vd.PowerOn() {
+		t.Fatal("Expected the display off at 14:30")
+	}
+
+	// A press only wakes the display.
+	board.Button2.(*hal.FakePin).Press()
+	run(time.Second)
+	if !vd.PowerOn() || vd.Brightness() != a.config.Night.Brightness {
+		t.Error("Expected the display woken at night brightness")
+	}
+	if a.displayManager.CurrentDisplay() != screen {
+		t.Errorf("Expected the screen to stay, got %d", a.displayManager.CurrentDisplay())
+	}
+	run(a.config.Night.Wake)
+	if vd.PowerOn() {
+		t.Error("Expected the display off again after the wake period")
+	}
+
+	// Without a trusted time the schedule is not applied.
+	a.timeSource = TimeUnknown
+	run(time.Second)
+	if !vd.PowerOn() || vd.Brightness() != a.config.Display.Brightness {
+		t.Error("Expected day brightness without a trusted time")
+	}
+}
+
+func TestNightConfigPersists(t *testing.T) {
+	st := store.NewMemStore()
+	cfg := DefaultConfig()
+	cfg.Night.Off = Period{From: 22*60 + 30, Until: 6*60 + 15}
+	if err := SaveConfig(st, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	got, err := LoadConfig(st, DefaultConfig())
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got.Night.Off != cfg.Night.Off {
+		t.Errorf("Expected off period %s, got %s", cfg.Night.Off, got.Night.Off)
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"pico_co2/internal/store"
+)
+
+const (
+	configKey = "config"
+
+	// configVersion is the schema version written by SaveConfig. Bump it
+	// and append to configMigrations whenever the stored schema changes,
+	// even when fields are only added: older firmware rejects a newer
+	// version instead of dropping the fields it does not know on its next
+	// save.
+	configVersion = 2
+
+	configHeaderSize = 6 // version uint16 | crc32 uint32
+)
+
+var (
+	ErrConfigCorrupt = errors.New("config: checksum mismatch")
+	ErrConfigVersion = errors.New("config: unsupported schema version")
+)
+
+// configMigrations[i] upgrades a decoded config of version i+1 to i+2.
+var configMigrations = []func(m map[string]any) error{
+	// 1->2 added Display.Brightness, Night, BurnIn, Carousel,
+	// Buttons.Gestures, Outputs, Alerts, Calibration, Forecast, TimeZone,
+	// Telemetry, Units, HiddenScreens and SensorMode. Their defaults apply.
+	func(m map[string]any) error { return nil },
+}
+
+// LoadConfig reads the stored config on top of defaults, so fields added
+// after the config was saved keep their default values. When nothing is
+// stored the defaults are returned unchanged. On error the defaults are
+// returned as well.
+func LoadConfig(st store.Store, defaults Config) (Config, error) {
+	data, err := st.Get(configKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+
+	if len(data) < configHeaderSize {
+		return defaults, ErrConfigCorrupt
+	}
+	version := int(binary.LittleEndian.Uint16(data[0:2]))
+	sum := binary.LittleEndian.Uint32(data[2:6])
+	payload := data[configHeaderSize:]
+	if configChecksum(data[0:2], payload) != sum {
+		return defaults, ErrConfigCorrupt
+	}
+	if version < 1 || version > configVersion {
+		return defaults, fmt.Errorf("%w: %d", ErrConfigVersion, version)
+	}
+
+	if version < configVersion {
+		payload, err = migrateConfig(payload, version)
+		if err != nil {
+			return defaults, err
+		}
+	}
+
+	cfg := defaults
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		return defaults, fmt.Errorf("config decode: %w", err)
+	}
+	return cfg, nil
+}
+
+// SaveConfig stores cfg with the current schema version.
+func SaveConfig(st store.Store, cfg Config) error {
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, configHeaderSize, configHeaderSize+len(payload))
+	binary.LittleEndian.PutUint16(data[0:2], configVersion)
+	binary.LittleEndian.PutUint32(data[2:6], configChecksum(data[0:2], payload))
+	data = append(data, payload...)
+
+	return st.Put(configKey, data)
+}
+
+func migrateConfig(payload []byte, version int) ([]byte, error) {
+	var m map[string]any
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return nil, fmt.Errorf("config decode: %w", err)
+	}
+	for v := version; v < configVersion; v++ {
+		if err := configMigrations[v-1](m); err != nil {
+			return nil, fmt.Errorf("config migration %d->%d: %w", v, v+1, err)
+		}
+	}
+	return json.Marshal(m)
+}
+
+func configChecksum(version, payload []byte) uint32 {
+	sum := crc32.ChecksumIEEE(version)
+	return crc32.Update(sum, crc32.IEEETable, payload)
+}
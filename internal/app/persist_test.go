@@ -0,0 +1,125 @@
+package app
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/store"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	st := store.NewMemStore()
+
+	cfg := DefaultConfig()
+	cfg.Intervals.Sensors = 30 * time.Second
+	cfg.DefaultDisplayIndex = 3
+	if err := SaveConfig(st, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfig(st, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Intervals.Sensors != 30*time.Second || got.DefaultDisplayIndex != 3 {
+		t.Errorf("Expected stored values, got %v and %d",
+			got.Intervals.Sensors, got.DefaultDisplayIndex)
+	}
+}
+
+func TestLoadConfigRejectsBadRecords(t *testing.T) {
+	st := store.NewMemStore()
+	SaveConfig(st, DefaultConfig())
+
+	data, _ := st.Get(configKey)
+	data[len(data)-1] ^= 0xFF
+	st.Put(configKey, data)
+	if _, err := LoadConfig(st, DefaultConfig()); !errors.Is(err, ErrConfigCorrupt) {
+		t.Errorf("Expected ErrConfigCorrupt, got %v", err)
+	}
+
+	// A config written by newer firmware is ignored.
+	data[len(data)-1] ^= 0xFF
+	data[0] = configVersion + 1
+	sum := configChecksum(data[0:2], data[configHeaderSize:])
+	data[2], data[3], data[4], data[5] = byte(sum), byte(sum>>8), byte(sum>>16), byte(sum>>24)
+	st.Put(configKey, data)
+	if _, err := LoadConfig(st, DefaultConfig()); !errors.Is(err, ErrConfigVersion) {
+		t.Errorf("Expected ErrConfigVersion, got %v", err)
+	}
+}
+
+// configV1 is a record as stored by the first schema version, before the
+// display, alert and sensor settings were added.
+const configV1 = `{"Display":{"Width":128,"Height":32,"Address":60},` +
+	`"I2C":{"Frequency":400000,"SDA":4,"SCL":5},"Buttons":{"Button1":10,"Button2":11},` +
+	`"Timeouts":{"Startup":60000000000,"Minute":60000000000,"Second":1000000000},` +
+	`"Intervals":{"RTC":1000000000,"Sensors":30000000000,"Input":50000000,"Watchdog":1000000000,"Persist":30000000000},` +
+	`"Recovery":{"Retries":2,"Backoff":5000000,"ReinitAfter":3},` +
+	`"Sensors":["aht20","ens160","scd4x"],"QueueCapacity":480,"DefaultDisplayIndex":3}`
+
+func TestLoadConfigMigratesOldVersions(t *testing.T) {
+	if len(configMigrations) != configVersion-1 {
+		t.Fatalf("Expected %d migrations for version %d, got %d",
+			configVersion-1, configVersion, len(configMigrations))
+	}
+
+	st := store.NewMemStore()
+	data := make([]byte, configHeaderSize, configHeaderSize+len(configV1))
+	binary.LittleEndian.PutUint16(data[0:2], 1)
+	binary.LittleEndian.PutUint32(data[2:6], configChecksum(data[0:2], []byte(configV1)))
+	st.Put(configKey, append(data, configV1...))
+
+	defaults := DefaultConfig()
+	got, err := LoadConfig(st, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Intervals.Sensors != 30*time.Second || got.DefaultDisplayIndex != 3 {
+		t.Errorf("Expected stored values, got %v and %d",
+			got.Intervals.Sensors, got.DefaultDisplayIndex)
+	}
+	if got.Display.Brightness != defaults.Display.Brightness || got.Night != defaults.Night ||
+		len(got.Alerts.Rules) != len(defaults.Alerts.Rules) {
+		t.Errorf("Expected defaults for the fields added since version 1, got %+v and %+v",
+			got.Display, got.Night)
+	}
+
+	// The next save writes the current version.
+	if err := SaveConfig(st, got); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = st.Get(configKey)
+	if v := binary.LittleEndian.Uint16(data[0:2]); v != configVersion {
+		t.Errorf("Expected version %d after saving, got %d", configVersion, v)
+	}
+}
+
+func TestAppPersistsSelectedScreen(t *testing.T) {
+	a, board := newTestApp(t)
+	st := store.NewMemStore()
+	a.store = st
+	readings := a.newReadings()
+	s := a.newScheduler(readings)
+	s.RunPending()
+
+	board.Button2.(*hal.FakePin).Press()
+	board.Clock.Sleep(a.config.Intervals.Input)
+	s.RunPending()
+	if _, err := st.Get(configKey); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("Expected the write to be deferred, got %v", err)
+	}
+
+	board.Clock.Sleep(a.config.Intervals.Persist)
+	s.RunPending()
+	cfg, err := LoadConfig(st, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultDisplayIndex != 1 {
+		t.Errorf("Expected screen 1 to be stored, got %d", cfg.DefaultDisplayIndex)
+	}
+}
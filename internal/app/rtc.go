@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"pico_co2/internal/types"
+	"pico_co2/pkg/tz"
+)
+
+// BuildTime is the firmware build time in RFC 3339, injected at link time:
+//
+//	-ldflags "-X pico_co2/internal/app.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// It is used to start a lost RTC at a plausible time.
+var BuildTime string
+
+// TimeSource tells where the RTC time was last set from.
+type TimeSource uint8
+
+const (
+	TimeUnknown  TimeSource = iota // RTC lost power or was never set
+	TimeBuild                      // firmware build timestamp, only a lower bound
+	TimeConsole                    // set by hand on the serial console
+	TimeExternal                   // synchronised from a host or another reference
+)
+
+var TimeSourceStrings = [...]string{
+	"unknown",
+	"build",
+	"console",
+	"external",
+}
+
+func (s TimeSource) String() string {
+	if s > TimeExternal {
+		return "unknown"
+	}
+	return TimeSourceStrings[s]
+}
+
+// Trusted reports whether time from this source is good enough to show.
+func (s TimeSource) Trusted() bool {
+	return s == TimeConsole || s == TimeExternal
+}
+
+const (
+	timeSourceKey = "time_source"
+
+	minValidYear = 2024
+	maxValidYear = 2099
+)
+
+// initRTC starts the DS3231 and works out whether its time can be trusted.
+// The RTC oscillator stop flag tells whether it kept time since it was last
+// set; the source of that time is kept in the store.
+func (a *App) initRTC() error {
+	if ok := a.ds3231.Configure(); !ok {
+		return errors.New("failed to configure DS3231 sensor")
+	}
+	if !a.ds3231.IsRunning() {
+		if err := a.ds3231.SetRunning(true); err != nil {
+			return fmt.Errorf("ds3231 set running: %w", err)
+		}
+	}
+
+	now, err := a.ds3231.ReadTime()
+	valid := err == nil && a.ds3231.IsTimeValid() &&
+		now.Year() >= minValidYear && now.Year() <= maxValidYear
+
+	a.timeSource = TimeUnknown
+	if valid {
+		a.timeSource = a.loadTimeSource()
+	}
+
+	build, err := time.Parse(time.RFC3339, BuildTime)
+	if err == nil && (!valid || now.Before(build)) {
+		logln("RTC time invalid, starting from build time", build.Format(time.DateTime))
+		return a.SetTime(build, TimeBuild)
+	}
+
+	logln("RTC time source:", a.timeSource.String())
+	return nil
+}
+
+// SetTime sets the RTC to t and records where the time came from.
+func (a *App) SetTime(t time.Time, src TimeSource) error {
+	if t.Year() < minValidYear || t.Year() > maxValidYear {
+		return fmt.Errorf("year %d out of range %d-%d", t.Year(), minValidYear, maxValidYear)
+	}
+	if err := a.ds3231.SetTime(t.UTC()); err != nil {
+		return err
+	}
+
+	a.timeSource = src
+	if a.store != nil {
+		if err := a.store.Put(timeSourceKey, []byte{byte(src)}); err != nil {
+			logln("time source save failed:", err.Error())
+		}
+	}
+	logln("RTC time set to", t.UTC().Format(time.DateTime), "from", src.String())
+	return nil
+}
+
+// TimeSource returns where the RTC time was last set from.
+func (a *App) TimeSource() TimeSource {
+	return a.timeSource
+}
+
+// setTimeZone selects the zone used for displayed and wall-clock times.
+func (a *App) setTimeZone(spec string) error {
+	zone, err := tz.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("time zone %q: %w", spec, err)
+	}
+	a.zone = zone
+	return nil
+}
+
+// localTime converts a UTC time to the configured time zone.
+func (a *App) localTime(t time.Time) time.Time {
+	return a.zone.In(t)
+}
+
+// loadTimeSource returns the stored time source. Without a record the
+// time is not trusted, since older firmware set the RTC to a fixed date.
+func (a *App) loadTimeSource() TimeSource {
+	if a.store == nil {
+		return TimeUnknown
+	}
+	v, err := a.store.Get(timeSourceKey)
+	if err != nil || len(v) != 1 || TimeSource(v[0]) > TimeExternal {
+		return TimeUnknown
+	}
+	return TimeSource(v[0])
+}
+
+// invalidateTime forces the next RTC read to publish the minute, so screens
+// pick up a new time or trust state.
+func invalidateTime(readings *types.Readings) {
+	readings.Time.Minute = -1
+}
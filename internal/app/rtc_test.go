@@ -0,0 +1,119 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/store"
+)
+
+// fakeRTC is a DS3231 register file. Its time does not advance.
+type fakeRTC struct {
+	regs [0x13]byte
+}
+
+func (f *fakeRTC) tx(w, r []byte) error {
+	if len(w) == 0 {
+		return nil
+	}
+	reg := int(w[0])
+	copy(f.regs[reg:], w[1:])
+	copy(r, f.regs[reg:])
+	return nil
+}
+
+func (f *fakeRTC) powerLoss() {
+	f.regs[0x0F] |= 0x80 // OSF
+}
+
+func newRTCTestApp(t *testing.T, rtc *fakeRTC, st store.Store) *App {
+	t.Helper()
+	board, _ := newTestBoard()
+	board.I2C.(*hal.FakeI2C).Attach(ds3231Addr, rtc.tx)
+	board.Store = st
+	board.Console = &hal.FakeConsole{}
+
+	a, err := NewWithBoard(DefaultConfig(), board)
+	if err != nil {
+		t.Fatalf("NewWithBoard: %v", err)
+	}
+	return a
+}
+
+func TestRTCTrust(t *testing.T) {
+	rtc := &fakeRTC{}
+	rtc.powerLoss()
+	st := store.NewMemStore()
+
+	a := newRTCTestApp(t, rtc, st)
+	if a.TimeSource() != TimeUnknown {
+		t.Errorf("Expected unknown time after power loss, got %v", a.TimeSource())
+	}
+
+	readings := a.newReadings()
+	a.readTime(readings)
+	if readings.Time.Trusted {
+		t.Error("Expected time not to be trusted")
+	}
+
+	a.newShell(readings, nil).Exec("time set 2026-10-16T12:00")
+	a.readTime(readings)
+	if !readings.Time.Trusted || readings.Time.Hour != 12 {
+		t.Errorf("Expected trusted 12:00, got %02d:%02d trusted=%v",
+			readings.Time.Hour, readings.Time.Minute, readings.Time.Trusted)
+	}
+	if rtc.regs[0x0F]&0x80 != 0 {
+		t.Error("Expected oscillator stop flag to be cleared")
+	}
+
+	// After a reboot the stored source is trusted again while the RTC kept
+	// running, but not after another power loss.
+	if a := newRTCTestApp(t, rtc, st); a.TimeSource() != TimeConsole {
+		t.Errorf("Expected console time after reboot, got %v", a.TimeSource())
+	}
+	rtc.powerLoss()
+	if a := newRTCTestApp(t, rtc, st); a.TimeSource() != TimeUnknown {
+		t.Errorf("Expected unknown time after power loss, got %v", a.TimeSource())
+	}
+}
+
+func TestRTCStartsFromBuildTime(t *testing.T) {
+	defer func(s string) { BuildTime = s }(BuildTime)
+	BuildTime = "2026-10-01T08:30:00Z"
+
+	rtc := &fakeRTC{}
+	rtc.powerLoss()
+	a := newRTCTestApp(t, rtc, nil)
+
+	now, _ := a.ds3231.ReadTime()
+	if want := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC); !now.Equal(want) {
+		t.Errorf("Expected RTC at build time %v, got %v", want, now)
+	}
+	if a.TimeSource() != TimeBuild || a.TimeSource().Trusted() {
+		t.Errorf("Expected untrusted build time, got %v", a.TimeSource())
+	}
+}
+
+func TestRTCKeepsUTCAndShowsLocalTime(t *testing.T) {
+	rtc := &fakeRTC{}
+	a := newRTCTestApp(t, rtc, nil)
+	readings := a.newReadings()
+	sh := a.newShell(readings, nil)
+
+	sh.Exec("set zone CET-1CEST,M3.5.0,M10.5.0/3")
+	sh.Exec("time set 2026-07-01T12:00")
+
+	now, _ := a.ds3231.ReadTime()
+	if want := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC); !now.Equal(want) {
+		t.Errorf("Expected RTC in UTC %v, got %v", want, now)
+	}
+
+	a.readTime(readings)
+	if readings.Time.Hour != 12 || readings.Time.Minute != 0 {
+		t.Errorf("Expected local 12:00, got %02d:%02d", readings.Time.Hour, readings.Time.Minute)
+	}
+	if a.config.TimeZone != "CET-1CEST,M3.5.0,M10.5.0/3" {
+		t.Errorf("Expected zone in config, got %q", a.config.TimeZone)
+	}
+}
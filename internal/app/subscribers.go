@@ -0,0 +1,51 @@
+package app
+
+import (
+	"pico_co2/internal/button"
+	"pico_co2/internal/events"
+	"pico_co2/internal/telemetry"
+)
+
+// subscribeDisplay switches screens on button presses, follows the night
+// schedule and redraws whenever something shown on the display changed.
+func (a *App) subscribeDisplay() {
+	a.events.Subscribe(func(e events.Event) {
+		r := e.Readings
+		switch e.Kind {
+		case events.MinuteChanged:
+			a.updateDisplayMode(r)
+		case events.ButtonPressed:
+			if e.Gesture != button.ShortPress && e.Gesture != button.Repeat {
+				break
+			}
+			switch e.Button {
+			case 1:
+				a.stepScreen(-1)
+			case 2:
+				a.stepScreen(1)
+			}
+		}
+		r.IsDrawen = false
+	}, events.ButtonPressed, events.MinuteChanged, events.ReadingAdded)
+}
+
+// subscribeLogger writes a telemetry record for every reading and logs
+// sensor errors and alerts to the serial console.
+func (a *App) subscribeLogger() {
+	a.events.Subscribe(func(e events.Event) {
+		switch e.Kind {
+		case events.ReadingAdded:
+			rec := telemetry.NewRecord(a.config.Telemetry.Device, a.rtcNow(e.Readings), e.Readings)
+			if err := a.telemetry.Encode(rec); err != nil {
+				logln("telemetry:", err.Error())
+			}
+		case events.SensorError:
+			h := e.Sensor
+			logln("sensor", h.Name, h.State.String(), "failures:", h.Failures, h.LastError)
+		case events.AlertChanged:
+			al := e.Alert
+			logln("alert", al.Rule.Name, al.State.String(), al.Rule.Severity.String(),
+				"value", int(al.Value))
+		}
+	}, events.ReadingAdded, events.SensorError, events.AlertChanged)
+}
@@ -4,20 +4,20 @@ import (
 	"sync/atomic"
 	"time"
 
-	"machine"
+	"pico_co2/internal/hal"
 )
 
 type TouchButton struct {
-	pin  machine.Pin
-	flag uint32
-	last time.Time
+	pin   hal.InputPin
+	clock hal.Clock
+	flag  uint32
+	last  time.Time
 }
 
-func NewTouchButton(p machine.Pin) *TouchButton {
-	b := &TouchButton{pin: p}
-	b.pin.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
-	b.pin.SetInterrupt(machine.PinRising, func(machine.Pin) {
-		now := time.Now()
+func NewTouchButton(p hal.InputPin, clock hal.Clock) *TouchButton {
+	b := &TouchButton{pin: p, clock: clock}
+	b.pin.SetInterrupt(func() {
+		now := b.clock.Now()
 		if now.Sub(b.last) < 50*time.Millisecond {
 			return
 		}
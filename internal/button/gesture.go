@@ -0,0 +1,174 @@
+package button
+
+import (
+	"time"
+
+	"pico_co2/internal/hal"
+)
+
+// Gesture is what the user did with the buttons.
+type Gesture uint8
+
+const (
+	ShortPress  Gesture = iota // released before LongPress
+	LongPress                  // held for LongPress
+	Repeat                     // still held, every RepeatEvery after LongPress
+	DoublePress                // a short press within DoubleGap of the previous one, after its ShortPress
+	Chord                      // both buttons pressed within ChordWindow
+)
+
+var GestureStrings = [...]string{
+	"short",
+	"long",
+	"repeat",
+	"double",
+	"chord",
+}
+
+func (g Gesture) String() string {
+	if g > Chord {
+		return "unknown"
+	}
+	return GestureStrings[g]
+}
+
+// GestureConfig holds the gesture timings. A zero duration disables the
+// gesture that depends on it.
+type GestureConfig struct {
+	LongPress   time.Duration // hold time of a long press
+	RepeatEvery time.Duration // repeat period while held after a long press
+	DoubleGap   time.Duration // longest gap between the presses of a double press
+	ChordWindow time.Duration // longest delay between the presses of a chord
+}
+
+func DefaultGestureConfig() GestureConfig {
+	return GestureConfig{
+		LongPress:   800 * time.Millisecond,
+		RepeatEvery: 250 * time.Millisecond,
+		DoubleGap:   300 * time.Millisecond,
+		ChordWindow: 150 * time.Millisecond,
+	}
+}
+
+// GestureEvent is a recognised gesture.
+type GestureEvent struct {
+	Gesture Gesture
+	Button  int // 1 or 2, 0 for Chord
+}
+
+// Gestures recognises gestures on two buttons from their press and release
+// times. Short presses are reported on release without waiting for a
+// possible second press, so a double press also yields two ShortPress
+// events.
+type Gestures struct {
+	config   GestureConfig
+	clock    hal.Clock
+	buttons  [2]gestureState
+	suppress bool
+}
+
+type gestureState struct {
+	button     *TouchButton
+	down       bool
+	pressedAt  time.Time
+	releasedAt time.Time // end of the last short press, zero after a double press
+	long       bool      // LongPress was reported for this press
+	nextRepeat time.Time
+	chord      bool // part of a chord, the release is not reported
+}
+
+func NewGestures(b1, b2 *TouchButton, clock hal.Clock, config GestureConfig) *Gestures {
+	g := &Gestures{config: config, clock: clock}
+	g.buttons[0].button = b1
+	g.buttons[1].button = b2
+	return g
+}
+
+// Suppress drops the gestures of the current presses until every button
+// is released, e.g. when a press only woke the device.
+func (g *Gestures) Suppress() {
+	g.suppress = true
+}
+
+// Poll samples the buttons and calls emit for every recognised gesture. It
+// should be called every few tens of milliseconds; presses shorter than
+// that are still caught by the button interrupt.
+func (g *Gestures) Poll(emit func(GestureEvent)) {
+	now := g.clock.Now()
+	send := func(gesture Gesture, button int) {
+		if !g.suppress {
+			emit(GestureEvent{Gesture: gesture, Button: button})
+		}
+	}
+
+	var held [2]bool
+	for i := range g.buttons {
+		s := &g.buttons[i]
+		tapped := s.button.Consume()
+		held[i] = s.button.pin.Get()
+		if !s.down && (held[i] || tapped) {
+			s.down, s.pressedAt = true, now
+			s.long, s.chord = false, false
+		}
+	}
+
+	b1, b2 := &g.buttons[0], &g.buttons[1]
+	if w := g.config.ChordWindow; w > 0 && b1.down && b2.down && !b1.chord && !b2.chord &&
+		!b1.long && !b2.long && absDuration(b1.pressedAt.Sub(b2.pressedAt)) <= w {
+		b1.chord, b2.chord = true, true
+		b1.releasedAt, b2.releasedAt = time.Time{}, time.Time{}
+		send(Chord, 0)
+	}
+
+	for i := range g.buttons {
+		s := &g.buttons[i]
+		if !s.down {
+			continue
+		}
+		if !held[i] {
+			s.down = false
+			if !s.long && !s.chord {
+				g.release(s, now, i+1, send)
+			}
+			continue
+		}
+		if s.chord {
+			continue
+		}
+
+		c := g.config
+		switch {
+		case !s.long && c.LongPress > 0 && now.Sub(s.pressedAt) >= c.LongPress:
+			s.long, s.releasedAt = true, time.Time{}
+			s.nextRepeat = now.Add(c.RepeatEvery)
+			send(LongPress, i+1)
+		case s.long && c.RepeatEvery > 0 && !now.Before(s.nextRepeat):
+			s.nextRepeat = s.nextRepeat.Add(c.RepeatEvery)
+			send(Repeat, i+1)
+		}
+	}
+
+	if !b1.down && !b2.down {
+		g.suppress = false
+	}
+}
+
+// release reports a short press and, if it closely follows the previous
+// one, a double press.
+func (g *Gestures) release(s *gestureState, now time.Time, button int, send func(Gesture, int)) {
+	send(ShortPress, button)
+	if gap := g.config.DoubleGap; gap > 0 && !s.releasedAt.IsZero() &&
+		s.pressedAt.Sub(s.releasedAt) <= gap {
+		s.releasedAt = time.Time{}
+		send(DoublePress, button)
+		return
+	}
+	s.releasedAt = now
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,115 @@
+package button
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+)
+
+type gestureTest struct {
+	clock  *hal.FakeClock
+	pins   [2]*hal.FakePin
+	g      *Gestures
+	events []GestureEvent
+}
+
+func newGestureTest() *gestureTest {
+	gt := &gestureTest{
+		clock: hal.NewFakeClock(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)),
+		pins:  [2]*hal.FakePin{{}, {}},
+	}
+	gt.g = NewGestures(
+		NewTouchButton(gt.pins[0], gt.clock),
+		NewTouchButton(gt.pins[1], gt.clock),
+		gt.clock,
+		DefaultGestureConfig(),
+	)
+	return gt
+}
+
+// run polls every 50 ms for d.
+func (gt *gestureTest) run(d time.Duration) {
+	for end := gt.clock.Now().Add(d); gt.clock.Now().Before(end); {
+		gt.g.Poll(func(e GestureEvent) { gt.events = append(gt.events, e) })
+		gt.clock.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (gt *gestureTest) take() []GestureEvent {
+	events := gt.events
+	gt.events = nil
+	return events
+}
+
+func TestGesturesShortAndDouble(t *testing.T) {
+	gt := newGestureTest()
+
+	// A tap shorter than the poll period is caught by the interrupt.
+	gt.pins[1].Press()
+	gt.run(time.Second)
+	if got, want := gt.take(), []GestureEvent{{ShortPress, 2}}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	gt.pins[0].Press()
+	gt.run(200 * time.Millisecond)
+	gt.pins[0].Press()
+	gt.run(time.Second)
+	want := []GestureEvent{{ShortPress, 1}, {ShortPress, 1}, {DoublePress, 1}}
+	if got := gt.take(); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestGesturesLongPressAndRepeat(t *testing.T) {
+	gt := newGestureTest()
+
+	gt.pins[0].Set(true)
+	gt.run(1400 * time.Millisecond)
+	gt.pins[0].Set(false)
+	gt.run(time.Second)
+
+	got := gt.take()
+	want := []GestureEvent{{LongPress, 1}, {Repeat, 1}, {Repeat, 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestGesturesChord(t *testing.T) {
+	gt := newGestureTest()
+
+	gt.pins[0].Set(true)
+	gt.run(100 * time.Millisecond)
+	gt.pins[1].Set(true)
+	gt.run(2 * time.Second)
+	gt.pins[0].Set(false)
+	gt.pins[1].Set(false)
+	gt.run(time.Second)
+
+	if got, want := gt.take(), []GestureEvent{{Chord, 0}}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestGesturesSuppress(t *testing.T) {
+	gt := newGestureTest()
+
+	gt.pins[0].Set(true)
+	gt.run(100 * time.Millisecond)
+	gt.g.Suppress()
+	gt.run(2 * time.Second)
+	gt.pins[0].Set(false)
+	gt.run(100 * time.Millisecond)
+	if got := gt.take(); len(got) != 0 {
+		t.Errorf("Expected no gestures while suppressed, got %v", got)
+	}
+
+	gt.pins[0].Press()
+	gt.run(100 * time.Millisecond)
+	if got, want := gt.take(), []GestureEvent{{ShortPress, 1}}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v after release, got %v", want, got)
+	}
+}
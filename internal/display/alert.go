@@ -0,0 +1,47 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"pico_co2/internal/display/font"
+	"pico_co2/internal/types"
+)
+
+// RenderAlert shows the most severe active alert and its current value.
+func RenderAlert(renderer Renderer, r *types.Readings) {
+	if renderer == nil {
+		return
+	}
+
+	renderer.Clear()
+
+	var (
+		lf = renderer.GetFont(font.FreemonoRegular18)
+		sf = renderer.GetFont(font.ProggySZ8)
+	)
+
+	width, _ := renderer.Size()
+
+	if len(r.Alerts) == 0 {
+		text := "No alerts"
+		sf.Print((width-sf.CalcWidth(text))/2, 12, text)
+		renderer.Display()
+		return
+	}
+
+	a := r.Alerts[0]
+	header := strings.ToUpper(a.Severity.String()) + " " + a.Name
+	if a.Snoozed {
+		header += " zz"
+	}
+	if more := len(r.Alerts) - 1; more > 0 {
+		header += fmt.Sprintf(" +%d", more)
+	}
+	sf.Print(0, 1, header)
+
+	value := fmt.Sprintf("%.0f", a.Value)
+	lf.Print((width-lf.CalcWidth(value))/2, 10, value)
+
+	renderer.Display()
+}
@@ -1,7 +1,6 @@
 package display
 
 import (
-	"fmt"
 	"pico_co2/internal/display/font"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
@@ -19,39 +18,51 @@ func RenderBars(renderer Renderer, r *types.Readings) {
 		sf          = renderer.GetFont(font.ProggySZ8)
 	)
 
-	renderer.DrawTwoSideBar(
-		36,
-		lineY,
-		int16(status.CO2Index(r.Raw.CO2)),
-		"CO2",
-		0,
-		4,
-	)
-	co2 := fmt.Sprintf("%d", r.Raw.CO2)
+	if r.Raw.Valid.Has(types.CO2) {
+		renderer.DrawTwoSideBar(
+			36,
+			lineY,
+			int16(status.CO2Index(r.Raw.CO2)),
+			"CO2",
+			0,
+			4,
+		)
+	} else {
+		sf.Print(36, lineY, "CO2")
+	}
+	co2 := formatCO2(r)
 	sf.Print(128-renderer.CalcSmallTextWidth(co2), lineY, co2)
 
 	lineY = 11
-	renderer.DrawTwoSideBar(
-		0,
-		lineY,
-		int16(status.CalculateComfortIndex(r.Raw.Temperature, r.Raw.Humidity)),
-		"TEM",
-		3,
-		4,
-	)
-	tem := fmt.Sprintf("%.0f", r.Raw.Temperature)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		renderer.DrawTwoSideBar(
+			0,
+			lineY,
+			int16(status.CalculateComfortIndex(r.Raw.Temperature, r.Raw.Humidity)),
+			"TEM",
+			3,
+			4,
+		)
+	} else {
+		sf.Print(0, lineY, "TEM")
+	}
+	tem := formatTemperature(r)
 	sf.Print(128-renderer.CalcSmallTextWidth(tem), lineY, tem)
 
 	lineY = 22
-	renderer.DrawTwoSideBar(
-		0,
-		lineY,
-		int16(status.HumidityComfortIndex(r.Raw.Humidity)),
-		"HUM",
-		3,
-		4,
-	)
-	hum := fmt.Sprintf("%.0f", r.Raw.Humidity)
+	if r.Raw.Valid.Has(types.Humidity) {
+		renderer.DrawTwoSideBar(
+			0,
+			lineY,
+			int16(status.HumidityComfortIndex(r.Raw.Humidity)),
+			"HUM",
+			3,
+			4,
+		)
+	} else {
+		sf.Print(0, lineY, "HUM")
+	}
+	hum := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	sf.Print(128-renderer.CalcSmallTextWidth(hum), lineY, hum)
 
 	renderer.Display()
@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-	"math"
 	"pico_co2/internal/display/font"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
@@ -20,14 +18,18 @@ func RenderBarsWithLargeNums(renderer Renderer, r *types.Readings) {
 		x         int16
 		co2status int16
 		lf       = renderer.GetFont(font.FreemonoRegular9)
-		// sf        = renderer.GetFont(font.ProggySZ8)
+		sf        = renderer.GetFont(font.ProggySZ8)
 	)
 
 	width, _ := renderer.Size()
 
 	// First line
-	heatIndex := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
-	x = renderer.DrawTwoSideBar(x, y, int16(heatIndex), "T", 0, 2)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		heatIndex := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
+		x = renderer.DrawTwoSideBar(x, y, int16(heatIndex), "T", 0, 2)
+	} else {
+		sf.Print(x, y, "T "+missingValue)
+	}
 
 
 	// https://backend.orbit.dtu.dk/ws/portalfiles/portal/348932926/1-s2.0-S0360132323011459-main_1_.pdf
@@ -40,21 +42,19 @@ func RenderBarsWithLargeNums(renderer Renderer, r *types.Readings) {
 		co2status = 2
 	}
 	x = 96
-	renderer.DrawTwoSideBar(x, y, co2status, "C", 0, 2)
+	if r.Raw.Valid.Has(types.CO2) {
+		renderer.DrawTwoSideBar(x, y, co2status, "C", 0, 2)
+	} else {
+		sf.Print(x, y, "C "+missingValue)
+	}
 
 	// second line
 	x = 0
 	y = 16
-	tempStr := fmt.Sprintf(
-		"%.0f",
-		math.Round(float64(r.Raw.Temperature)),
-	)
+	tempStr := formatTemperature(r)
 	lf.Print(x, y, tempStr)
-	humStr := fmt.Sprintf(
-		"%.0f",
-		math.Round(float64(r.Raw.Humidity)),
-	)
-	co2str := fmt.Sprintf("%d", r.Raw.CO2)
+	humStr := formatRounded(r, types.Humidity, r.Raw.Humidity)
+	co2str := formatCO2(r)
 	xHum := lf.CalcWidth(tempStr) + (width - lf.CalcWidth(tempStr) - lf.CalcWidth(humStr) - lf.CalcWidth(co2str))/2
 	lf.Print(xHum, y, humStr)
 
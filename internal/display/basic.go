@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-	"math"
 	"pico_co2/internal/display/font"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
@@ -23,17 +21,21 @@ func RenderBasic(renderer Renderer, r *types.Readings) {
 		sf          = renderer.GetFont(font.ProggySZ8)
 	)
 
-	lf.Print(0, 0, fmt.Sprintf("%s", status.CO2Index(r.Raw.CO2)))
+	co2Index := missingValue
+	if r.Raw.Valid.Has(types.CO2) {
+		co2Index = status.CO2Index(r.Raw.CO2).String()
+	}
+	lf.Print(0, 0, co2Index)
 
-	humStr := fmt.Sprintf("H %.0f", math.Round(float64(r.Raw.Humidity)))
+	humStr := "H " + formatRounded(r, types.Humidity, r.Raw.Humidity)
 	humWidth := sf.CalcWidth(humStr)
 	sf.Print(width-humWidth, 24, humStr)
 
-	tempStr := fmt.Sprintf("T %.0f", math.Round(float64(r.Raw.Temperature)))
+	tempStr := "T " + formatTemperature(r)
 	tempWidth := sf.CalcWidth(tempStr)
 	sf.Print(width-tempWidth-space-humWidth, 24, tempStr)
 
-	co2Str := fmt.Sprintf("CO2 %d", r.Raw.CO2)
+	co2Str := "CO2 " + formatCO2(r)
 	sf.Print(0, 24, co2Str)
 
 	renderer.Display()
@@ -0,0 +1,168 @@
+package display
+
+import (
+	"time"
+
+	"pico_co2/internal/display/font"
+	"pico_co2/internal/types"
+)
+
+// BurnInConfig controls the OLED burn-in protection. A zero duration
+// disables the corresponding measure.
+type BurnInConfig struct {
+	ShiftEvery   time.Duration // how long the picture stays before moving one pixel
+	MaxShift     int16         // largest offset in each direction, in pixels
+	RefreshEvery time.Duration // how often the panel is inverted or blanked
+	RefreshFor   time.Duration // how long the panel stays inverted or blank
+	Blank        bool          // blank the panel instead of inverting it
+	SaverAfter   time.Duration // inactivity before the moving clock screensaver
+}
+
+// DefaultBurnInConfig moves the picture within ±2 pixels every two minutes
+// and inverts the panel for half a minute every hour. The screensaver is
+// off.
+func DefaultBurnInConfig() BurnInConfig {
+	return BurnInConfig{
+		ShiftEvery:   2 * time.Minute,
+		MaxShift:     2,
+		RefreshEvery: time.Hour,
+		RefreshFor:   30 * time.Second,
+	}
+}
+
+// BurnIn spreads the wear of static screens over the panel. It wraps the
+// render functions of MethodRegistry, so they draw at fixed coordinates
+// and need not know about it.
+type BurnIn struct {
+	config   BurnInConfig
+	clock    types.Clock
+	activity time.Time
+}
+
+func NewBurnIn(config BurnInConfig, clock types.Clock) *BurnIn {
+	return &BurnIn{
+		config:   config,
+		clock:    clock,
+		activity: clock.Now(),
+	}
+}
+
+// Activity records a user interaction, which ends the screensaver.
+func (b *BurnIn) Activity() {
+	b.activity = b.clock.Now()
+}
+
+// Render draws the screen fn with the current offset, or a blank frame or
+// the screensaver instead.
+func (b *BurnIn) Render(renderer Renderer, fn func(Renderer, *types.Readings), r *types.Readings) {
+	if renderer == nil {
+		return
+	}
+
+	now := b.clock.Now()
+	refresh := b.refreshing(now)
+	renderer.SetOffset(b.Offset(now))
+	renderer.SetInverted(refresh && !b.config.Blank)
+
+	switch {
+	case refresh && b.config.Blank:
+		renderer.Clear()
+		renderer.Display()
+	case b.saverActive(now) && r.Alert() == nil:
+		RenderScreensaver(renderer, r)
+	default:
+		fn(renderer, r)
+	}
+}
+
+// Offset returns the pixel shift at now. It walks back and forth over a
+// square of side 2*MaxShift+1 in a snake pattern, one pixel per step.
+func (b *BurnIn) Offset(now time.Time) (dx, dy int16) {
+	m := b.config.MaxShift
+	if b.config.ShiftEvery <= 0 || m <= 0 {
+		return 0, 0
+	}
+
+	side := int64(2*m + 1)
+	cells := side * side
+	n := now.UnixNano() / int64(b.config.ShiftEvery) % (2 * cells)
+	if n >= cells {
+		n = 2*cells - 1 - n
+	}
+	row, col := n/side, n%side
+	if row%2 == 1 {
+		col = side - 1 - col
+	}
+	return int16(col) - m, int16(row) - m
+}
+
+// NextChange returns when the rendered picture changes next without new
+// readings, or zero if it never does.
+func (b *BurnIn) NextChange(now time.Time) time.Time {
+	var next time.Time
+	earliest := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+
+	if c := b.config; c.ShiftEvery > 0 && c.MaxShift > 0 {
+		earliest(now.Add(c.ShiftEvery - phase(now, c.ShiftEvery)))
+	}
+	if c := b.config; c.RefreshEvery > 0 && c.RefreshFor > 0 {
+		if p := phase(now, c.RefreshEvery); p < c.RefreshFor {
+			earliest(now.Add(c.RefreshFor - p))
+		} else {
+			earliest(now.Add(c.RefreshEvery - p))
+		}
+	}
+	if saver := b.activity.Add(b.config.SaverAfter); b.config.SaverAfter > 0 && now.Before(saver) {
+		earliest(saver)
+	}
+	return next
+}
+
+func (b *BurnIn) refreshing(now time.Time) bool {
+	c := b.config
+	if c.RefreshEvery <= 0 || c.RefreshFor <= 0 {
+		return false
+	}
+	return phase(now, c.RefreshEvery) < c.RefreshFor
+}
+
+// phase returns the time elapsed since the last multiple of d after the
+// Unix epoch, so all periods line up with Offset.
+func phase(now time.Time, d time.Duration) time.Duration {
+	return time.Duration(now.UnixNano() % int64(d))
+}
+
+func (b *BurnIn) saverActive(now time.Time) bool {
+	return b.config.SaverAfter > 0 && now.Sub(b.activity) >= b.config.SaverAfter
+}
+
+// RenderScreensaver shows the time and CO2 in small print, at a position
+// that changes every minute.
+func RenderScreensaver(renderer Renderer, r *types.Readings) {
+	if renderer == nil {
+		return
+	}
+
+	renderer.Clear()
+
+	sf := renderer.GetFont(font.ProggySZ8)
+	width, height := renderer.Size()
+
+	text := formatTime(r)
+	if r.Raw.Valid.Has(types.CO2) {
+		text += " " + formatCO2(r)
+	}
+
+	// Large strides keep consecutive positions far apart. The products
+	// overflow int16 late in the day, so they are taken in int.
+	minutes := int(max(int16(r.Time.Hour*60+r.Time.Minute), 0))
+	freeX := int(max(width-sf.CalcWidth(text), 1))
+	freeY := int(max(height-sf.Height(), 1))
+	sf.Print(int16(minutes*37%freeX), int16(minutes*11%freeY), text)
+
+	renderer.Display()
+}
@@ -0,0 +1,115 @@
+package display
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"pico_co2/internal/display/font"
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+)
+
+func TestBurnInOffsetMovesOnePixel(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	b := NewBurnIn(DefaultBurnInConfig(), clock)
+	m := DefaultBurnInConfig().MaxShift
+
+	seen := map[[2]int16]bool{}
+	px, py := b.Offset(clock.Now())
+	for range 100 {
+		next := b.NextChange(clock.Now())
+		clock.Sleep(next.Sub(clock.Now()))
+		dx, dy := b.Offset(clock.Now())
+		if dx < -m || dx > m || dy < -m || dy > m {
+			t.Fatalf("Expected offsets within ±%d, got %d,%d", m, dx, dy)
+		}
+		if step := abs(dx-px) + abs(dy-py); step > 1 {
+			t.Fatalf("Expected moves of at most one pixel, got %d,%d -> %d,%d", px, py, dx, dy)
+		}
+		seen[[2]int16{dx, dy}] = true
+		px, py = dx, dy
+	}
+	if want := int((2*m + 1) * (2*m + 1)); len(seen) != want {
+		t.Errorf("Expected all %d offsets to be used, got %d", want, len(seen))
+	}
+}
+
+func TestBurnInRender(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	cfg := BurnInConfig{
+		ShiftEvery:   time.Minute,
+		MaxShift:     1,
+		RefreshEvery: time.Hour,
+		RefreshFor:   time.Minute,
+		SaverAfter:   10 * time.Minute,
+	}
+	b := NewBurnIn(cfg, clock)
+	vd := NewVirtualDisplay(128, 32)
+	r := types.InitReadings(1)
+	white := color.RGBA{255, 255, 255, 255}
+
+	var drawn int
+	dot := func(renderer Renderer, r *types.Readings) {
+		drawn++
+		renderer.Clear()
+		renderer.SetPixel(10, 10, white)
+	}
+
+	b.Render(vd, dot, r)
+	dx, dy := b.Offset(clock.Now())
+	if vd.buffer[(10+dy)*128+10+dx] != white {
+		t.Errorf("Expected the pixel shifted by %d,%d", dx, dy)
+	}
+	if !vd.Inverted() {
+		t.Error("Expected the panel inverted at the start of the hour")
+	}
+
+	clock.Sleep(time.Minute)
+	b.Render(vd, dot, r)
+	if vd.Inverted() {
+		t.Error("Expected the panel back to normal after RefreshFor")
+	}
+
+	clock.Sleep(cfg.SaverAfter)
+	b.Render(vd, dot, r)
+	if drawn != 2 {
+		t.Errorf("Expected the screensaver after inactivity, screen drawn %d times", drawn)
+	}
+	b.Activity()
+	b.Render(vd, dot, r)
+	if drawn != 3 {
+		t.Error("Expected the screen again after activity")
+	}
+}
+
+func TestScreensaverStaysOnScreenLateInTheDay(t *testing.T) {
+	r := types.InitReadings(1)
+	r.Time.Trusted = true
+	r.Raw.CO2 = 800
+	r.Raw.Valid = types.CO2
+	white := color.RGBA{255, 255, 255, 255}
+	lit := func(vd *VirtualDisplay) (n int) {
+		for _, c := range vd.buffer {
+			if c == white {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, at := range [][2]int{{0, 0}, {14, 46}, {23, 59}} {
+		r.Time.Hour, r.Time.Minute = at[0], at[1]
+		vd := NewVirtualDisplay(128, 32)
+		RenderScreensaver(vd, r)
+
+		// The same text drawn at the origin shows every pixel, so a clipped
+		// screensaver lights fewer.
+		ref := NewVirtualDisplay(128, 32)
+		ref.GetFont(font.ProggySZ8).Print(0, 0, formatTime(r)+" "+formatCO2(r))
+		if got, want := lit(vd), lit(ref); got == 0 || got != want {
+			t.Errorf("At %02d:%02d expected the text within the screen with %d pixels, got %d",
+				at[0], at[1], want, got)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package display
 
 import (
-	"fmt"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
 )
@@ -20,25 +19,33 @@ func RenderCO2BarWithNums(renderer Renderer, r *types.Readings) {
 
 	x = 0
 	y = 0
-	hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
-	renderer.DrawTwoSideBar(x, y, int16(hi), "HEAT  ", 0, 4)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
+		renderer.DrawTwoSideBar(x, y, int16(hi), "HEAT  ", 0, 4)
+	} else {
+		renderer.DrawSmallText(x, y, "HEAT  "+missingValue)
+	}
 
 	x = 0
 	y = 11
-	co2status := int16(status.CO2Index(r.Raw.CO2))
-	renderer.DrawTwoSideBar(x, y, co2status, "CO2   ", 0, 4)
+	if r.Raw.Valid.Has(types.CO2) {
+		co2status := int16(status.CO2Index(r.Raw.CO2))
+		renderer.DrawTwoSideBar(x, y, co2status, "CO2   ", 0, 4)
+	} else {
+		renderer.DrawSmallText(x, y, "CO2   "+missingValue)
+	}
 
 	x = 0
 	y = 22
-	co2Str := fmt.Sprintf("       %d", r.Raw.CO2)
+	co2Str := "       " + formatCO2(r)
 	renderer.DrawSmallText(x, y, co2Str)
 
-	temp := fmt.Sprintf("%.0f", r.Raw.Temperature)
+	temp := formatTemperature(r)
 	x = 128 - renderer.CalcLargeTextWidth(temp)
 	y = 0
 	renderer.DrawLargeText(x, y, temp)
 
-	hum := fmt.Sprintf("%.0f", r.Raw.Humidity)
+	hum := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	x = 128 - renderer.CalcLargeTextWidth(hum)
 	y = 16
 	renderer.DrawLargeText(x, y, hum)
@@ -12,26 +12,50 @@ import (
 	"pico_co2/pkg/sparkline"
 )
 
+// SSD1306 commands used for brightness and power control.
+const (
+	ssd1306SetContrast  = 0x81
+	ssd1306SetPrecharge = 0xD9
+	ssd1306SetVCOMH     = 0xDB
+	ssd1306DisplayOff   = 0xAE
+	ssd1306DisplayOn    = 0xAF
+	ssd1306Normal       = 0xA6
+	ssd1306Invert       = 0xA7
+
+	// Below this level the precharge period and VCOMH level are lowered
+	// as well, which dims the panel further than the contrast alone.
+	ssd1306LowPowerBelow = 0x10
+)
+
+// commander is implemented by ssd1306.Device.
+type commander interface {
+	Command(command uint8)
+}
+
 // SSD1306Adapter wraps an ssd1306 display
 type SSD1306Adapter struct {
 	dev   drivers.Displayer
 	white color.RGBA
 	black color.RGBA
 	fonts *font.FontRegistry
+	dx    int16
+	dy    int16
 }
 
 func NewSSD1306Adapter(dev drivers.Displayer) *SSD1306Adapter {
 	white := color.RGBA{255, 255, 255, 255}
-	return &SSD1306Adapter{
+	v := &SSD1306Adapter{
 		dev:   dev,
 		white: white,
 		black: color.RGBA{0, 0, 0, 255},
-		fonts: font.NewFontRegistry(dev, white),
 	}
+	// Fonts draw through the adapter so that they follow SetOffset.
+	v.fonts = font.NewFontRegistry(v, white)
+	return v
 }
 
 func (v *SSD1306Adapter) SetPixel(x, y int16, c color.RGBA) {
-	v.dev.SetPixel(x, y, c)
+	v.dev.SetPixel(x+v.dx, y+v.dy, c)
 }
 
 func (v *SSD1306Adapter) Size() (int16, int16) {
@@ -52,6 +76,52 @@ func (v *SSD1306Adapter) Display() error {
 	return nil
 }
 
+func (v *SSD1306Adapter) SetBrightness(level uint8) {
+	dev, ok := v.dev.(commander)
+	if !ok {
+		return
+	}
+
+	precharge, vcomh := uint8(0xF1), uint8(0x20) // datasheet defaults
+	if level < ssd1306LowPowerBelow {
+		precharge, vcomh = 0xE1, 0x30
+	}
+	dev.Command(ssd1306SetContrast)
+	dev.Command(level)
+	dev.Command(ssd1306SetPrecharge)
+	dev.Command(precharge)
+	dev.Command(ssd1306SetVCOMH)
+	dev.Command(vcomh)
+}
+
+func (v *SSD1306Adapter) SetPower(on bool) {
+	dev, ok := v.dev.(commander)
+	if !ok {
+		return
+	}
+	if on {
+		dev.Command(ssd1306DisplayOn)
+	} else {
+		dev.Command(ssd1306DisplayOff)
+	}
+}
+
+func (v *SSD1306Adapter) SetOffset(dx, dy int16) {
+	v.dx, v.dy = dx, dy
+}
+
+func (v *SSD1306Adapter) SetInverted(on bool) {
+	dev, ok := v.dev.(commander)
+	if !ok {
+		return
+	}
+	if on {
+		dev.Command(ssd1306Invert)
+	} else {
+		dev.Command(ssd1306Normal)
+	}
+}
+
 // NEW: Unified font management methods
 func (v *SSD1306Adapter) GetFont(fontType font.FontType) font.FontPrinter {
 	if v == nil || v.fonts == nil {
@@ -17,12 +17,16 @@ import (
 )
 
 type VirtualDisplay struct {
-	buffer []color.RGBA
-	width  int16
-	height int16
-	white  color.RGBA
-	black  color.RGBA
-	fonts  *font.FontRegistry
+	buffer     []color.RGBA
+	width      int16
+	height     int16
+	white      color.RGBA
+	black      color.RGBA
+	fonts      *font.FontRegistry
+	brightness uint8
+	off        bool
+	dx, dy     int16
+	inverted   bool
 }
 
 func NewVirtualDisplay(w, h int16) *VirtualDisplay {
@@ -46,6 +50,7 @@ func (v *VirtualDisplay) Clear() {
 }
 
 func (v *VirtualDisplay) SetPixel(x, y int16, c color.RGBA) {
+	x, y = x+v.dx, y+v.dy
 	if x >= 0 && x < v.width && y >= 0 && y < v.height {
 		v.buffer[y*v.width+x] = c
 	}
@@ -59,6 +64,37 @@ func (v *VirtualDisplay) Display() error {
 	return nil
 }
 
+func (v *VirtualDisplay) SetBrightness(level uint8) {
+	v.brightness = level
+}
+
+func (v *VirtualDisplay) SetPower(on bool) {
+	v.off = !on
+}
+
+func (v *VirtualDisplay) SetOffset(dx, dy int16) {
+	v.dx, v.dy = dx, dy
+}
+
+func (v *VirtualDisplay) SetInverted(on bool) {
+	v.inverted = on
+}
+
+// Inverted reports whether the panel is inverted.
+func (v *VirtualDisplay) Inverted() bool {
+	return v.inverted
+}
+
+// Brightness returns the level last set with SetBrightness.
+func (v *VirtualDisplay) Brightness() uint8 {
+	return v.brightness
+}
+
+// PowerOn reports whether the panel is on.
+func (v *VirtualDisplay) PowerOn() bool {
+	return !v.off
+}
+
 // NEW: Unified font management methods
 func (v *VirtualDisplay) GetFont(fontType font.FontType) font.FontPrinter {
 	if v == nil || v.fonts == nil {
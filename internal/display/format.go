@@ -0,0 +1,58 @@
+package display
+
+import (
+	"fmt"
+	"math"
+
+	"pico_co2/internal/types"
+)
+
+// convertTemperatures converts a Celsius history to the unit of r.
+func convertTemperatures(r *types.Readings, data []int16) []int16 {
+	if r.TempUnit == types.Celsius {
+		return data
+	}
+	out := make([]int16, len(data))
+	for i, v := range data {
+		out[i] = int16(math.Round(float64(r.TempUnit.FromCelsius(float32(v)))))
+	}
+	return out
+}
+
+// missingValue is shown instead of a quantity no sensor currently provides.
+const missingValue = "--"
+
+// missingTime is shown instead of a clock that cannot be trusted.
+const missingTime = "--:--"
+
+// formatRounded formats v rounded to an integer, or missingValue when q is
+// not valid in r.
+func formatRounded(r *types.Readings, q types.Quantity, v float32) string {
+	if !r.Raw.Valid.Has(q) {
+		return missingValue
+	}
+	return fmt.Sprintf("%.0f", math.Round(float64(v)))
+}
+
+// formatTemperature formats the temperature in the unit of r rounded to an
+// integer, or missingValue when invalid.
+func formatTemperature(r *types.Readings) string {
+	return formatRounded(r, types.Temperature, r.TempUnit.FromCelsius(r.Raw.Temperature))
+}
+
+// formatCO2 formats the CO2 concentration, or missingValue when invalid.
+func formatCO2(r *types.Readings) string {
+	if !r.Raw.Valid.Has(types.CO2) {
+		return missingValue
+	}
+	return fmt.Sprintf("%d", r.Raw.CO2)
+}
+
+// formatTime formats the RTC time as H:MM, or missingTime when the RTC time
+// is not trusted.
+func formatTime(r *types.Readings) string {
+	if !r.Time.Trusted {
+		return missingTime
+	}
+	return fmt.Sprintf("%d:%02d", r.Time.Hour, r.Time.Minute)
+}
@@ -0,0 +1,30 @@
+package display
+
+import (
+	"slices"
+	"testing"
+
+	"pico_co2/internal/types"
+)
+
+func TestFormatTemperature(t *testing.T) {
+	r := types.InitReadings(1)
+	r.Raw.Temperature = 21.6
+	r.Raw.Valid = types.Temperature
+
+	if got := formatTemperature(r); got != "22" {
+		t.Errorf("Expected 22 °C, got %s", got)
+	}
+	r.TempUnit = types.Fahrenheit
+	if got := formatTemperature(r); got != "71" {
+		t.Errorf("Expected 71 °F, got %s", got)
+	}
+	if got := convertTemperatures(r, []int16{0, 100}); !slices.Equal(got, []int16{32, 212}) {
+		t.Errorf("Expected [32 212] °F, got %v", got)
+	}
+
+	r.Raw.Valid = 0
+	if got := formatTemperature(r); got != missingValue {
+		t.Errorf("Expected %s without a temperature, got %s", missingValue, got)
+	}
+}
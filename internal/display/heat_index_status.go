@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
 )
@@ -21,32 +19,49 @@ func RenderHeatIndexStatus(renderer Renderer, r *types.Readings) {
 
 	x = 0
 	y = 0
-	renderer.DrawTwoSideBar(x, y, int16(status.CO2Index(r.Raw.CO2)), "CO2 ", 0, 4)
+	if r.Raw.Valid.Has(types.CO2) {
+		renderer.DrawTwoSideBar(x, y, int16(status.CO2Index(r.Raw.CO2)), "CO2 ", 0, 4)
+	} else {
+		renderer.DrawSmallText(x, y, "CO2 "+missingValue)
+	}
 
-	co2Value := fmt.Sprintf("%d", r.Raw.CO2)
+	co2Value := formatCO2(r)
 	renderer.DrawLargeText(int16(width-renderer.CalcLargeTextWidth(co2Value)), y, co2Value)
 
 	// Heat Index status
 	x = 0
 	y = 11
-	hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
-	renderer.DrawTwoSideBar(x, y, int16(hi), "HI  ", 0, 4)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
+		renderer.DrawTwoSideBar(x, y, int16(hi), "HI  ", 0, 4)
+	} else {
+		renderer.DrawSmallText(x, y, "HI  "+missingValue)
+	}
 
 	y = 22
-	humStr := fmt.Sprintf("%.0f", r.Raw.Humidity)
+	humStr := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	humWidth := renderer.CalcSmallTextWidth(humStr)
 	renderer.DrawSmallText(int16(width-humWidth), y, humStr)
-	tempStr := fmt.Sprintf("%.0f", r.Raw.Temperature)
+	tempStr := formatTemperature(r)
 	tempWidth := renderer.CalcSmallTextWidth(tempStr)
 	renderer.DrawSmallText(int16(width-humWidth-tempWidth-5), y, tempStr)
 
-	status := status.ComfortStatus(
-		r.Raw.CO2,
-		r.Raw.AQI,
-		r.Raw.Humidity,
-		r.Raw.Temperature,
-	)
-	renderer.DrawSmallText(x, y, status)
+	comfort := missingValue
+	if r.Raw.Valid.Has(types.CO2 | types.Temperature | types.Humidity) {
+		// Without an AQI a moderate index neither flags poor air nor rules
+		// out comfort, so the status follows the other quantities.
+		aqi := uint8(status.Moderate)
+		if r.Raw.Valid.Has(types.AQI) {
+			aqi = r.Raw.AQI
+		}
+		comfort = status.ComfortStatus(
+			r.Raw.CO2,
+			aqi,
+			r.Raw.Humidity,
+			r.Raw.Temperature,
+		)
+	}
+	renderer.DrawSmallText(x, y, comfort)
 
 	renderer.Display()
 }
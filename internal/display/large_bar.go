@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
 )
@@ -20,24 +18,28 @@ func RenderLargeBar(renderer Renderer, r *types.Readings) {
 		YPos int16 = 0
 		co2index	 = status.CO2Index(r.Raw.CO2)
 	)
-	renderer.DrawSmallText(XPos, YPos, co2index.String())
-
-	XPos = 0
-	YPos = 12
-	renderer.DrawSquareBar(XPos, YPos, uint8(co2index))
+	if r.Raw.Valid.Has(types.CO2) {
+		renderer.DrawSmallText(XPos, YPos, co2index.String())
+
+		XPos = 0
+		YPos = 12
+		renderer.DrawSquareBar(XPos, YPos, uint8(co2index))
+	} else {
+		renderer.DrawSmallText(XPos, YPos, missingValue)
+	}
 
-	co2Str := fmt.Sprintf("CO2 %d", r.Raw.CO2)
+	co2Str := "CO2 " + formatCO2(r)
 	XPos = 0
 	YPos = 24
 	renderer.DrawSmallText(XPos, YPos, co2Str)
 
-	humStr := fmt.Sprintf("H %.0f", r.Raw.Humidity)
+	humStr := "H " + formatRounded(r, types.Humidity, r.Raw.Humidity)
 	humWidth := renderer.CalcSmallTextWidth(humStr)
 	XPos = int16(width - humWidth)
 	YPos = 24
 	renderer.DrawSmallText(XPos, YPos, humStr)
 
-	tempStr := fmt.Sprintf("T %.0f", r.Raw.Temperature)
+	tempStr := "T " + formatTemperature(r)
 	tempWidth := renderer.CalcSmallTextWidth(tempStr)
 	XPos = int16(width - (humWidth) - (tempWidth) - 8) // 8 for padding
 	YPos = 24
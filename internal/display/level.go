@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-	"math"
 	"pico_co2/internal/display/font"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
@@ -30,6 +28,10 @@ func RenderLevel(renderer Renderer, r *types.Readings) {
 		trend    = r.Calculated.CO2Trend
 	)
 
+	if !r.Raw.Valid.Has(types.CO2) {
+		trend = status.UnknownCO2Trend
+	}
+
 	switch trend {
 	case status.RisingCO2:
 		arrow = "⬆"
@@ -43,6 +45,8 @@ func RenderLevel(renderer Renderer, r *types.Readings) {
 
 	// https://backend.orbit.dtu.dk/ws/portalfiles/portal/348932926/1-s2.0-S0360132323011459-main_1_.pdf
 	switch {
+	case !r.Raw.Valid.Has(types.CO2):
+		decision = missingValue + " CO"
 	case r.Raw.CO2 <= 800:
 		decision = "OK CO"
 	case r.Raw.CO2 <= 1000:
@@ -60,9 +64,9 @@ func RenderLevel(renderer Renderer, r *types.Readings) {
 	ne.Print(decisionWidth, 0, arrow)
 
 	// Line 2: Three metrics (small font) - Temperature, Humidity, CO2
-	tempStr := fmt.Sprintf("%.0f C", math.Round(float64(r.Raw.Temperature)))
-	humStr := fmt.Sprintf("%.0f %%", math.Round(float64(r.Raw.Humidity)))
-	co2Str := fmt.Sprintf("%d", r.Raw.CO2)
+	tempStr := formatTemperature(r) + " " + r.TempUnit.String()
+	humStr := formatRounded(r, types.Humidity, r.Raw.Humidity) + " %"
+	co2Str := formatCO2(r)
 
 	// Calculate widths for proper spacing
 	tempWidth := sf.CalcWidth(tempStr)
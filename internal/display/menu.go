@@ -0,0 +1,54 @@
+package display
+
+import (
+	"fmt"
+
+	"pico_co2/internal/display/font"
+)
+
+// MenuView is the state of the settings menu as shown on the display.
+type MenuView struct {
+	Title   string // label of the open submenu
+	Index   int    // 1-based position of the selected entry
+	Count   int
+	Label   string
+	Value   string
+	Editing bool
+	Message string // result of the last action, replaces the hint
+}
+
+// RenderMenu shows the selected menu entry with its value, and the button
+// hints or the last message below.
+func RenderMenu(renderer Renderer, v MenuView) {
+	if renderer == nil {
+		return
+	}
+
+	renderer.Clear()
+
+	sf := renderer.GetFont(font.ProggySZ8)
+	width, _ := renderer.Size()
+
+	pos := fmt.Sprintf("%d/%d", v.Index, v.Count)
+	sf.Print(0, 0, v.Title)
+	sf.Print(width-sf.CalcWidth(pos), 0, pos)
+
+	label := v.Label
+	value := v.Value
+	if v.Editing {
+		value = "<" + value + ">"
+	}
+	sf.Print(0, 11, label)
+	sf.Print(width-sf.CalcWidth(value), 11, value)
+
+	hint := v.Message
+	if hint == "" {
+		hint = "1:next 2:open hold1:back"
+		if v.Editing {
+			hint = "1:- 2:+ both:save"
+		}
+	}
+	sf.Print(0, 22, hint)
+
+	renderer.Display()
+}
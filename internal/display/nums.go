@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
 )
@@ -18,17 +16,21 @@ func RenderNums(renderer Renderer, r *types.Readings) {
 		x int16
 	)
 
-	renderer.DrawSmallText(x, y, fmt.Sprintf("CO2: %s", status.CO2Index(r.Raw.CO2)))
+	co2Index := missingValue
+	if r.Raw.Valid.Has(types.CO2) {
+		co2Index = status.CO2Index(r.Raw.CO2).String()
+	}
+	renderer.DrawSmallText(x, y, "CO2: "+co2Index)
 
 	x = 0
 	y = 8
-	renderer.DrawXLargeText(x, y, fmt.Sprintf("%d", r.Raw.CO2))
+	renderer.DrawXLargeText(x, y, formatCO2(r))
 
 	x = 90
 	y = 0
 	renderer.DrawSmallText(x, y, "T")
 
-	temp := fmt.Sprintf("%.0f", r.Raw.Temperature)
+	temp := formatTemperature(r)
 	x = 128 - renderer.CalcLargeTextWidth(temp)
 	y = 0
 	renderer.DrawLargeText(x, y, temp)
@@ -37,7 +39,7 @@ func RenderNums(renderer Renderer, r *types.Readings) {
 	y = 16
 	renderer.DrawSmallText(x, y, "H")
 
-	hum := fmt.Sprintf("%.0f", r.Raw.Humidity)
+	hum := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	x = 128 - renderer.CalcLargeTextWidth(hum)
 	y = 16
 	renderer.DrawLargeText(x, y, hum)
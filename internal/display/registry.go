@@ -25,5 +25,17 @@ var MethodRegistry = []RenderMethod{
 	{"RenderSparklineHI", RenderSparklineHI},
 	{"RenderSparklineT", RenderSparklineT},
 	{"RenderSparklineRH", RenderSparklineRH},
+	{"RenderAlert", RenderAlert},
+	{"RenderVentilation", RenderVentilation},
 	// {"RenderTempHumid", RenderTempHumid},
 }
+
+// ScreenIndex returns the MethodRegistry index of the named screen.
+func ScreenIndex(name string) (int, bool) {
+	for i, m := range MethodRegistry {
+		if m.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -10,6 +10,17 @@ type Renderer interface {
 	Size() (width, height int16)
 	Clear()
 	Display() error
+
+	// SetBrightness sets the panel brightness; 0 is the dimmest level that
+	// is still visible.
+	SetBrightness(level uint8)
+	// SetPower turns the panel on or off. The frame buffer is kept.
+	SetPower(on bool)
+	// SetOffset shifts everything drawn afterwards by dx, dy pixels.
+	SetOffset(dx, dy int16)
+	// SetInverted swaps lit and dark pixels on the panel.
+	SetInverted(on bool)
+
 	DrawPlot(data []int16, title string)
 	DrawTwoSideBar(
 		x, y int16,
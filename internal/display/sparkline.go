@@ -15,9 +15,9 @@ func RenderSparklineCO2(renderer Renderer, r *types.Readings) {
 }
 
 func RenderSparklineT(renderer Renderer, r *types.Readings) {
-	data := r.History.Temperature.Contiguous()
+	data := convertTemperatures(r, r.History.Temperature.Contiguous())
 	title := "T"
-	baseline := int16(27)
+	baseline := int16(r.TempUnit.FromCelsius(27))
 
 	renderSparkline(renderer, title, data, baseline)
 }
@@ -31,9 +31,9 @@ func RenderSparklineRH(renderer Renderer, r *types.Readings) {
 }
 
 func RenderSparklineHI(renderer Renderer, r *types.Readings) {
-	data := r.History.HeatIndexTemp.Contiguous()
+	data := convertTemperatures(r, r.History.HeatIndexTemp.Contiguous())
 	title := "HI"
-	baseline := int16(27)
+	baseline := int16(r.TempUnit.FromCelsius(27))
 
 	renderSparkline(renderer, title, data, baseline)
 }
@@ -1,9 +1,7 @@
 package display
 
 import (
-	"fmt"
 	"image/color"
-	"math"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
 )
@@ -23,26 +21,30 @@ func RenderTempHumid(renderer Renderer, r *types.Readings) {
 	var verticalBarWidth int16 = 4
 	var spacing int16 = 20
 
-	temp := fmt.Sprintf("%.0f", math.Round(float64(r.Raw.Temperature)))
+	temp := formatTemperature(r)
 	tempWidth := renderer.CalcXLargeTextWidth(temp)
 	xPos = int16(0)
 	yPos = int16(8)
 	renderer.DrawXLargeText(xPos, yPos, temp)
 	// TODO: move to driver
-	hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
-	DrawVerticalBar(renderer, tempWidth+4, yPos, int16(hi), 4)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		hi := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
+		DrawVerticalBar(renderer, tempWidth+4, yPos, int16(hi), 4)
+	}
 
-	hum := fmt.Sprintf("%.0f", math.Round(float64(r.Raw.Humidity)))
+	hum := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	humWidth := renderer.CalcXLargeTextWidth(hum)
 	xPos = tempWidth + verticalBarWidth + spacing
 	renderer.DrawXLargeText(xPos, yPos, hum)
-	DrawVerticalBar(
-		renderer,
-		xPos+humWidth+4,
-		yPos,
-		status.HumidityComfortIndex(r.Raw.Humidity),
-		4,
-	)
+	if r.Raw.Valid.Has(types.Humidity) {
+		DrawVerticalBar(
+			renderer,
+			xPos+humWidth+4,
+			yPos,
+			status.HumidityComfortIndex(r.Raw.Humidity),
+			4,
+		)
+	}
 
 	xPos = int16(0)
 	yPos = int16(0)
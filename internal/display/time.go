@@ -1,8 +1,6 @@
 package display
 
 import (
-	"fmt"
-	"math"
 	"pico_co2/internal/display/font"
 	"pico_co2/internal/types"
 	"pico_co2/internal/types/status"
@@ -27,11 +25,15 @@ func RenderTime(renderer Renderer, r *types.Readings) {
 
 	// First line
 
-	heatIndex := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
-	x = renderer.DrawTwoSideBar(x, y, int16(heatIndex), "H", 0, 2)
+	if r.Raw.Valid.Has(types.Temperature | types.Humidity) {
+		heatIndex := status.GetHeatIndex(r.Raw.Temperature, r.Raw.Humidity)
+		x = renderer.DrawTwoSideBar(x, y, int16(heatIndex), "H", 0, 2)
+	} else {
+		sf.Print(x, y, "H "+missingValue)
+	}
 
-	temp := fmt.Sprintf("%.0f", math.Round(float64(r.Raw.Temperature)))
-	hum := fmt.Sprintf("%.0f", math.Round(float64(r.Raw.Humidity)))
+	temp := formatTemperature(r)
+	hum := formatRounded(r, types.Humidity, r.Raw.Humidity)
 	x = width/2 - sf.CalcWidth(temp) - 2 - 1
 	sf.Print(x, y, temp)
 	x = width/2 + 2
@@ -47,11 +49,15 @@ func RenderTime(renderer Renderer, r *types.Readings) {
 		co2status = 2
 	}
 	x = 97
-	renderer.DrawTwoSideBar(x, y, co2status, "C", 0, 2)
+	if r.Raw.Valid.Has(types.CO2) {
+		renderer.DrawTwoSideBar(x, y, co2status, "C", 0, 2)
+	} else {
+		sf.Print(x, y, "C "+missingValue)
+	}
 
 	// second line
 	y = 10
-	timeStr := fmt.Sprintf("%d:%02d", r.Time.Hour, r.Time.Minute)
+	timeStr := formatTime(r)
 	xTime := (width - lf.CalcWidth(timeStr)) / 2
 	lf.Print(xTime, y, timeStr)
 
@@ -0,0 +1,66 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"pico_co2/internal/display/font"
+	"pico_co2/internal/types"
+)
+
+// RenderVentilation shows the CO2 slope and how long until the room should
+// be ventilated.
+func RenderVentilation(renderer Renderer, r *types.Readings) {
+	if renderer == nil {
+		return
+	}
+
+	renderer.Clear()
+
+	var (
+		lf = renderer.GetFont(font.FreemonoBold12)
+		sf = renderer.GetFont(font.ProggySZ8)
+		c  = r.Calculated
+	)
+
+	width, _ := renderer.Size()
+
+	sf.Print(0, 1, "CO2 "+formatCO2(r))
+	slope := missingValue + "/h"
+	if c.CO2SlopeValid {
+		slope = fmt.Sprintf("%+.0f/h", c.CO2Slope)
+	}
+	sf.Print(width-sf.CalcWidth(slope), 1, slope)
+
+	label, value := ventilationText(r)
+	sf.Print(0, 18, label)
+	x := sf.CalcWidth(label) + 4
+	lf.Print(x+(width-x-lf.CalcWidth(value))/2, 14, value)
+
+	renderer.Display()
+}
+
+// ventilationText returns the small label and the large value of the
+// ventilation estimate, e.g. "VENT in" and "12m".
+func ventilationText(r *types.Readings) (label, value string) {
+	c := r.Calculated
+	switch {
+	case !r.Raw.Valid.Has(types.CO2):
+		return "VENT", missingValue
+	case c.Ventilate:
+		return "VENT", "now"
+	case c.ThresholdIn > 0:
+		return "VENT in", formatDuration(c.ThresholdIn)
+	case c.CO2SlopeValid:
+		return "Air", "OK"
+	}
+	return "VENT", missingValue
+}
+
+// formatDuration formats d as minutes below 100 minutes and as hours above.
+func formatDuration(d time.Duration) string {
+	if d < 100*time.Minute {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh", int(d.Round(time.Hour).Hours()))
+}
@@ -0,0 +1,35 @@
+package display
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/display/font"
+	"pico_co2/internal/types"
+)
+
+func TestVentilationFits(t *testing.T) {
+	vd := NewVirtualDisplay(128, 32)
+	width, _ := vd.Size()
+	sf := vd.GetFont(font.ProggySZ8)
+	lf := vd.GetFont(font.FreemonoBold12)
+
+	r := types.InitReadings(1)
+	r.Raw.CO2 = 850
+	r.Raw.Valid = types.CO2
+	r.Calculated.CO2Slope = 1250
+	r.Calculated.CO2SlopeValid = true
+	for _, in := range []time.Duration{0, 12 * time.Minute, 99 * time.Minute, 48 * time.Hour} {
+		r.Calculated.ThresholdIn = in
+		label, value := ventilationText(r)
+		if w := sf.CalcWidth(label) + 4 + lf.CalcWidth(value); w > width {
+			t.Errorf("Expected %q %q to fit in %d px, got %d px", label, value, width, w)
+		}
+		RenderVentilation(vd, r)
+	}
+
+	r.Calculated.Ventilate = true
+	if label, value := ventilationText(r); label != "VENT" || value != "now" {
+		t.Errorf("Expected VENT now, got %q %q", label, value)
+	}
+}
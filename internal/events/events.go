@@ -0,0 +1,85 @@
+// Package events provides a synchronous publish/subscribe bus for the things
+// that happen in the main loop: new readings, minute changes, sensor errors,
+// button presses and alerts. Consumers such as the display, the serial logger
+// or storage subscribe independently of each other.
+package events
+
+import (
+	"time"
+
+	"pico_co2/internal/alert"
+	"pico_co2/internal/button"
+	"pico_co2/internal/types"
+)
+
+// Kind is the type of an event.
+type Kind uint8
+
+const (
+	ReadingAdded  Kind = iota // a sensor cycle finished and was stored
+	MinuteChanged             // the RTC minute changed
+	SensorError               // a sensor read failed
+	ButtonPressed             // a button gesture was recognised
+	AlertChanged              // an alert became active or cleared
+	numKinds
+)
+
+var KindStrings = [...]string{
+	"reading",
+	"minute",
+	"sensor-error",
+	"button",
+	"alert",
+}
+
+func (k Kind) String() string {
+	if k >= numKinds {
+		return "unknown"
+	}
+	return KindStrings[k]
+}
+
+// Event is published on the Bus. Readings is always set; the other fields
+// depend on Kind.
+type Event struct {
+	Kind     Kind
+	Time     time.Time
+	Readings *types.Readings
+	Raw      types.RawReadings   // ReadingAdded: values measured in this cycle
+	Sensor   *types.SensorHealth // SensorError
+	Button   int                 // ButtonPressed: 1 or 2, 0 for a chord
+	Gesture  button.Gesture      // ButtonPressed
+	Alert    *alert.Alert        // AlertChanged
+}
+
+// Handler receives published events.
+type Handler func(Event)
+
+// Bus dispatches events to subscribers in subscription order. Publish calls
+// the handlers synchronously, so it must not be called from interrupts.
+type Bus struct {
+	handlers [numKinds][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers h for the given event kinds.
+func (b *Bus) Subscribe(h Handler, kinds ...Kind) {
+	for _, k := range kinds {
+		if k < numKinds {
+			b.handlers[k] = append(b.handlers[k], h)
+		}
+	}
+}
+
+// Publish delivers e to every handler subscribed to e.Kind.
+func (b *Bus) Publish(e Event) {
+	if e.Kind >= numKinds {
+		return
+	}
+	for _, h := range b.handlers[e.Kind] {
+		h(e)
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"testing"
+
+	"pico_co2/internal/types"
+)
+
+func TestBusDeliversByKind(t *testing.T) {
+	bus := NewBus()
+	r := types.InitReadings(4)
+
+	var got []string
+	bus.Subscribe(func(e Event) { got = append(got, "a:"+e.Kind.String()) }, ReadingAdded, ButtonPressed)
+	bus.Subscribe(func(e Event) { got = append(got, "b:"+e.Kind.String()) }, ButtonPressed)
+
+	bus.Publish(Event{Kind: ReadingAdded, Readings: r})
+	bus.Publish(Event{Kind: ButtonPressed, Readings: r, Button: 1})
+	bus.Publish(Event{Kind: MinuteChanged, Readings: r})
+
+	want := []string{"a:reading", "a:button", "b:button"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
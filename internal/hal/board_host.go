@@ -0,0 +1,78 @@
+//go:build !tinygo
+
+package hal
+
+import (
+	"bufio"
+	"os"
+
+	"pico_co2/internal/store"
+)
+
+// NewBoard returns a simulated board for running the firmware on the host.
+// The I2C bus answers every address with zeros, so drivers see an idle bus.
+func NewBoard(cfg BoardConfig) (*Board, error) {
+	return &Board{
+		I2C:      NewFakeI2C(),
+		Button1:  &FakePin{},
+		Button2:  &FakePin{},
+		LED:      &FakeOutput{},
+		Buzzer:   &FakeOutput{},
+		Watchdog: &FakeWatchdog{},
+		Clock:    SystemClock{},
+		Console:  newStdioConsole(),
+		Reset:    func() { os.Exit(0) },
+	}, nil
+}
+
+// StorePath is the directory OpenStore keeps the settings in on the host.
+// When empty the settings are kept in memory and lost on exit, so running
+// the simulator or the tests never touches the user's files. Set it with
+// -ldflags "-X pico_co2/internal/hal.StorePath=dir".
+var StorePath string
+
+// OpenStore returns a store in StorePath, or in memory when it is empty.
+func OpenStore() (store.Store, error) {
+	if StorePath == "" {
+		return store.NewMemStore(), nil
+	}
+	return store.NewFileStore(StorePath)
+}
+
+// stdioConsole reads stdin in the background, so reads never block.
+type stdioConsole struct {
+	input chan byte
+}
+
+func newStdioConsole() *stdioConsole {
+	c := &stdioConsole{input: make(chan byte, 256)}
+	go func() {
+		r := bufio.NewReader(os.Stdin)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			c.input <- b
+		}
+	}()
+	return c
+}
+
+func (c *stdioConsole) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		select {
+		case b := <-c.input:
+			p[n] = b
+			n++
+		default:
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+func (c *stdioConsole) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
@@ -0,0 +1,157 @@
+//go:build tinygo
+
+package hal
+
+import (
+	"machine"
+	"time"
+
+	"pico_co2/internal/store"
+)
+
+// NewBoard configures the RP2040 peripherals described by cfg.
+func NewBoard(cfg BoardConfig) (*Board, error) {
+	bus := &i2cBus{
+		I2C: machine.I2C0,
+		config: machine.I2CConfig{
+			Frequency: cfg.I2CFrequency,
+			SDA:       machine.Pin(cfg.SDA),
+			SCL:       machine.Pin(cfg.SCL),
+		},
+	}
+	if err := bus.Configure(bus.config); err != nil {
+		return nil, err
+	}
+
+	timeout := uint32(cfg.WatchdogTimeout.Milliseconds())
+	if timeout == 0 || timeout > machine.WatchdogMaxTimeout {
+		timeout = machine.WatchdogMaxTimeout
+	}
+	wd := machine.Watchdog
+	if err := wd.Configure(machine.WatchdogConfig{TimeoutMillis: timeout}); err != nil {
+		return nil, err
+	}
+
+	board := &Board{
+		I2C:      bus,
+		Button1:  newGPIOInput(machine.Pin(cfg.Button1)),
+		Button2:  newGPIOInput(machine.Pin(cfg.Button2)),
+		Watchdog: wd,
+		Clock:    SystemClock{},
+		Console:  serialConsole{machine.Serial},
+		Reset:    machine.CPUReset,
+	}
+	if cfg.LED != NoPin {
+		board.LED = newGPIOOutput(machine.Pin(cfg.LED))
+	}
+	if cfg.Buzzer != NoPin {
+		board.Buzzer = newGPIOOutput(machine.Pin(cfg.Buzzer))
+	}
+	return board, nil
+}
+
+type gpioInput struct {
+	pin machine.Pin
+}
+
+func newGPIOInput(p machine.Pin) *gpioInput {
+	p.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
+	return &gpioInput{pin: p}
+}
+
+func (g *gpioInput) Get() bool {
+	return g.pin.Get()
+}
+
+func (g *gpioInput) SetInterrupt(fn func()) error {
+	return g.pin.SetInterrupt(machine.PinRising, func(machine.Pin) {
+		fn()
+	})
+}
+
+type gpioOutput struct {
+	pin machine.Pin
+}
+
+func newGPIOOutput(p machine.Pin) *gpioOutput {
+	p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	p.Low()
+	return &gpioOutput{pin: p}
+}
+
+func (g *gpioOutput) Set(high bool) {
+	g.pin.Set(high)
+}
+
+// i2cBus is the hardware I2C controller with bus clearing support.
+type i2cBus struct {
+	*machine.I2C
+	config machine.I2CConfig
+}
+
+// Stuck reads the SDA pad, which is high on an idle bus.
+func (b *i2cBus) Stuck() bool {
+	return !b.config.SDA.Get()
+}
+
+// ClearBus bit-bangs SCL until the slave releases SDA (at most 9 pulses),
+// generates a STOP condition and hands the pins back to the controller.
+// The lines are only ever pulled low; high is left to the pull-ups, as on
+// the open-drain bus the display, RTC and sensors share.
+func (b *i2cBus) ClearBus() error {
+	const halfPeriod = 5 * time.Microsecond // ~100 kHz
+
+	sda, scl := b.config.SDA, b.config.SCL
+	low := func(p machine.Pin) {
+		p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		p.Low()
+	}
+	release := func(p machine.Pin) {
+		p.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	}
+	release(sda)
+	release(scl)
+	time.Sleep(halfPeriod)
+
+	for i := 0; i < 9 && !sda.Get(); i++ {
+		low(scl)
+		time.Sleep(halfPeriod)
+		release(scl)
+		time.Sleep(halfPeriod)
+	}
+
+	// STOP: SDA rises while SCL is high.
+	low(scl)
+	low(sda)
+	time.Sleep(halfPeriod)
+	release(scl)
+	time.Sleep(halfPeriod)
+	release(sda)
+	time.Sleep(halfPeriod)
+
+	return b.Configure(b.config)
+}
+
+// OpenStore returns the key-value store kept in the flash area after the
+// firmware image.
+func OpenStore() (store.Store, error) {
+	return store.NewBlockStore(machine.Flash)
+}
+
+// serialConsole makes reads from the USB serial port non-blocking.
+type serialConsole struct {
+	machine.Serialer
+}
+
+func (c serialConsole) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && c.Buffered() > 0 {
+		b, err := c.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
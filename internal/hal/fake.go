@@ -0,0 +1,221 @@
+package hal
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// FakeDevice emulates a single I2C peripheral. It receives the write buffer
+// and fills the read buffer of a transaction.
+type FakeDevice func(w, r []byte) error
+
+// FakeI2C is an in-memory I2C bus. Transactions to addresses without a
+// registered device succeed and read back zeros.
+type FakeI2C struct {
+	mu      sync.Mutex
+	devices map[uint16]FakeDevice
+	txCount int
+	clears  int
+	stuck   bool
+}
+
+// NewFakeI2C returns an empty fake bus.
+func NewFakeI2C() *FakeI2C {
+	return &FakeI2C{devices: make(map[uint16]FakeDevice)}
+}
+
+// Attach registers dev at addr, replacing any previous device.
+func (b *FakeI2C) Attach(addr uint16, dev FakeDevice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.devices[addr] = dev
+}
+
+// Tx implements drivers.I2C.
+func (b *FakeI2C) Tx(addr uint16, w, r []byte) error {
+	b.mu.Lock()
+	dev := b.devices[addr]
+	b.txCount++
+	b.mu.Unlock()
+
+	if dev == nil {
+		for i := range r {
+			r[i] = 0
+		}
+		return nil
+	}
+	return dev(w, r)
+}
+
+// TxCount returns the number of transactions issued on the bus.
+func (b *FakeI2C) TxCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.txCount
+}
+
+// SetStuck simulates a slave holding SDA low until the bus is cleared.
+func (b *FakeI2C) SetStuck(stuck bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stuck = stuck
+}
+
+// Stuck implements BusClearer.
+func (b *FakeI2C) Stuck() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stuck
+}
+
+// ClearBus implements BusClearer by counting the calls and releasing SDA.
+func (b *FakeI2C) ClearBus() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clears++
+	b.stuck = false
+	return nil
+}
+
+// Clears returns how often the bus was cleared.
+func (b *FakeI2C) Clears() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.clears
+}
+
+// FakePin is an input pin driven by tests.
+type FakePin struct {
+	mu       sync.Mutex
+	level    bool
+	onRising func()
+}
+
+func (p *FakePin) Get() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.level
+}
+
+func (p *FakePin) SetInterrupt(fn func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onRising = fn
+	return nil
+}
+
+// Set drives the pin level, firing the interrupt on a rising edge.
+func (p *FakePin) Set(level bool) {
+	p.mu.Lock()
+	rising := level && !p.level
+	p.level = level
+	fn := p.onRising
+	p.mu.Unlock()
+
+	if rising && fn != nil {
+		fn()
+	}
+}
+
+// Press emulates a short touch: a rising edge followed by release.
+func (p *FakePin) Press() {
+	p.Set(true)
+	p.Set(false)
+}
+
+// FakeOutput records the level of an output pin.
+type FakeOutput struct {
+	mu    sync.Mutex
+	level bool
+	edges int
+}
+
+func (o *FakeOutput) Set(high bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if high && !o.level {
+		o.edges++
+	}
+	o.level = high
+}
+
+// Level returns the current level.
+func (o *FakeOutput) Level() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.level
+}
+
+// Pulses returns the number of rising edges.
+func (o *FakeOutput) Pulses() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.edges
+}
+
+// FakeWatchdog counts how often it was fed.
+type FakeWatchdog struct {
+	Started bool
+	Updates int
+}
+
+func (w *FakeWatchdog) Start() error {
+	w.Started = true
+	return nil
+}
+
+func (w *FakeWatchdog) Update() {
+	w.Updates++
+}
+
+// FakeClock is a manually advanced Clock. Sleep advances the clock instead
+// of blocking, so time-driven code runs instantly in tests.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a clock stopped at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// FakeConsole is a serial console with input queued by tests.
+type FakeConsole struct {
+	In  bytes.Buffer
+	Out bytes.Buffer
+}
+
+// Read returns queued input, or no data once the input is consumed.
+func (c *FakeConsole) Read(p []byte) (int, error) {
+	if c.In.Len() == 0 {
+		return 0, nil
+	}
+	return c.In.Read(p)
+}
+
+func (c *FakeConsole) Write(p []byte) (int, error) {
+	return c.Out.Write(p)
+}
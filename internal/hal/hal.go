@@ -0,0 +1,81 @@
+// Package hal abstracts the board peripherals used by the application, so the
+// same code runs on the Pico (TinyGo build) and on the host (tests and the
+// desktop simulator).
+package hal
+
+import (
+	"io"
+	"time"
+
+	"pico_co2/internal/store"
+
+	"tinygo.org/x/drivers"
+)
+
+// InputPin is a GPIO pin configured as input.
+type InputPin interface {
+	// Get returns the current level of the pin.
+	Get() bool
+	// SetInterrupt registers fn to be called on every rising edge.
+	SetInterrupt(fn func()) error
+}
+
+// OutputPin is a GPIO pin configured as output.
+type OutputPin interface {
+	Set(high bool)
+}
+
+// NoPin marks an output that is not fitted.
+const NoPin = 0xFF
+
+// Watchdog resets the board when it is not fed in time.
+type Watchdog interface {
+	Start() error
+	Update()
+}
+
+// Clock provides the current time and blocking sleeps.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+}
+
+// BoardConfig describes how the board peripherals are wired.
+type BoardConfig struct {
+	I2CFrequency    uint32
+	SDA             uint8
+	SCL             uint8
+	Button1         uint8
+	Button2         uint8
+	LED             uint8         // NoPin if not fitted
+	Buzzer          uint8         // NoPin if not fitted
+	WatchdogTimeout time.Duration // zero selects the maximum timeout
+}
+
+// Board bundles all peripherals the application depends on.
+type Board struct {
+	I2C      drivers.I2C
+	Button1  InputPin
+	Button2  InputPin
+	LED      OutputPin // nil if not fitted
+	Buzzer   OutputPin // nil if not fitted
+	Watchdog Watchdog
+	Clock    Clock
+	Store    store.Store // nil disables persistence
+
+	// Console is the serial console. Reads return immediately with no
+	// data when no input is pending. Nil disables the command shell.
+	Console io.ReadWriter
+	// Reset reboots the board; nil when not supported.
+	Reset func()
+}
+
+// SystemClock is a Clock backed by the time package.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+func (SystemClock) Since(t time.Time) time.Duration { return time.Since(t) }
+
+func (SystemClock) Sleep(d time.Duration) { time.Sleep(d) }
@@ -0,0 +1,77 @@
+package hal
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// BusClearer is implemented by buses that can free a slave holding SDA low,
+// e.g. after a brown-out in the middle of a transfer.
+type BusClearer interface {
+	// Stuck reports whether SDA is held low while the bus is idle.
+	Stuck() bool
+	// ClearBus clocks out up to 9 SCL pulses, issues a STOP condition and
+	// re-initialises the bus controller.
+	ClearBus() error
+}
+
+// RecoveringI2C retries failed transactions with exponential backoff. The
+// bus is only cleared when a slave holds SDA low; a plain NACK from a busy
+// or sleeping device is just retried.
+type RecoveringI2C struct {
+	bus     drivers.I2C
+	clock   Clock
+	retries int
+	backoff time.Duration
+
+	// Log receives recovery failures; they are dropped while it is nil.
+	Log func(args ...any)
+}
+
+// NewRecoveringI2C wraps bus. Each transaction is attempted up to retries+1
+// times; the delay before the first retry is backoff and doubles afterwards.
+func NewRecoveringI2C(
+	bus drivers.I2C,
+	clock Clock,
+	retries int,
+	backoff time.Duration,
+) *RecoveringI2C {
+	return &RecoveringI2C{
+		bus:     bus,
+		clock:   clock,
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+// Tx implements drivers.I2C.
+func (b *RecoveringI2C) Tx(addr uint16, w, r []byte) error {
+	delay := b.backoff
+	err := b.bus.Tx(addr, w, r)
+	for attempt := 0; err != nil && attempt < b.retries; attempt++ {
+		b.clearIfStuck()
+		b.clock.Sleep(delay)
+		delay *= 2
+
+		err = b.bus.Tx(addr, w, r)
+	}
+	return err
+}
+
+// TxOnce sends a transaction without retrying, for commands that are
+// expected to be NACKed, like the SCD41 wake-up. Drivers detect it with a
+// type assertion on their bus.
+func (b *RecoveringI2C) TxOnce(addr uint16, w, r []byte) error {
+	return b.bus.Tx(addr, w, r)
+}
+
+func (b *RecoveringI2C) clearIfStuck() {
+	c, ok := b.bus.(BusClearer)
+	if !ok || !c.Stuck() {
+		return
+	}
+	if err := c.ClearBus(); err != nil && b.Log != nil {
+		b.Log("i2c bus clear failed:", err.Error())
+	}
+}
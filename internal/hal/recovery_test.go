@@ -0,0 +1,105 @@
+package hal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecoveringI2CRetriesWithBackoff(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	bus := NewFakeI2C()
+
+	failures := 2
+	bus.Attach(0x62, func(w, r []byte) error {
+		if failures > 0 {
+			failures--
+			return errors.New("nack")
+		}
+		return nil
+	})
+
+	rb := NewRecoveringI2C(bus, clock, 3, 10*time.Millisecond)
+	if err := rb.Tx(0x62, []byte{0x00}, nil); err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if got := bus.TxCount(); got != 3 {
+		t.Errorf("Expected 3 transactions, got %d", got)
+	}
+	if got := bus.Clears(); got != 0 {
+		t.Errorf("Expected no bus clear after a NACK, got %d", got)
+	}
+	if got := clock.Since(start); got != 30*time.Millisecond {
+		t.Errorf("Expected 10ms+20ms backoff, got %v", got)
+	}
+}
+
+func TestRecoveringI2CClearsStuckBus(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	bus := NewFakeI2C()
+	bus.Attach(0x62, func(w, r []byte) error {
+		if bus.Stuck() {
+			return errors.New("arbitration lost")
+		}
+		return nil
+	})
+	bus.SetStuck(true)
+
+	rb := NewRecoveringI2C(bus, clock, 3, time.Millisecond)
+	if err := rb.Tx(0x62, []byte{0x00}, nil); err != nil {
+		t.Fatalf("Expected success after clearing the bus, got %v", err)
+	}
+	if got := bus.Clears(); got != 1 {
+		t.Errorf("Expected 1 bus clear, got %d", got)
+	}
+}
+
+func TestRecoveringI2CGivesUp(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	bus := NewFakeI2C()
+	errNack := errors.New("nack")
+	bus.Attach(0x62, func(w, r []byte) error { return errNack })
+
+	rb := NewRecoveringI2C(bus, clock, 2, time.Millisecond)
+	if err := rb.Tx(0x62, []byte{0x00}, nil); !errors.Is(err, errNack) {
+		t.Fatalf("Expected %v, got %v", errNack, err)
+	}
+	if got := bus.TxCount(); got != 3 {
+		t.Errorf("Expected 3 transactions, got %d", got)
+	}
+}
+
+// unclearableI2C is a stuck bus that cannot be cleared.
+type unclearableI2C struct{ *FakeI2C }
+
+func (unclearableI2C) ClearBus() error { return errors.New("SDA still low") }
+
+func TestRecoveringI2CTxOnce(t *testing.T) {
+	bus := NewFakeI2C()
+	errNack := errors.New("nack")
+	bus.Attach(0x62, func(w, r []byte) error { return errNack })
+
+	rb := NewRecoveringI2C(bus, NewFakeClock(time.Time{}), 3, time.Millisecond)
+	if err := rb.TxOnce(0x62, []byte{0x36, 0xF6}, nil); !errors.Is(err, errNack) {
+		t.Fatalf("Expected %v, got %v", errNack, err)
+	}
+	if got := bus.TxCount(); got != 1 {
+		t.Errorf("Expected 1 transaction, got %d", got)
+	}
+}
+
+func TestRecoveringI2CLogsClearFailure(t *testing.T) {
+	bus := NewFakeI2C()
+	bus.Attach(0x62, func(w, r []byte) error { return errors.New("arbitration lost") })
+	bus.SetStuck(true)
+
+	var logged []any
+	rb := NewRecoveringI2C(unclearableI2C{bus}, NewFakeClock(time.Time{}), 1, time.Millisecond)
+	rb.Log = func(args ...any) { logged = append(logged, args...) }
+	rb.Tx(0x62, []byte{0x00}, nil)
+
+	if len(logged) != 2 || logged[1] != "SDA still low" {
+		t.Errorf("Expected the clear failure to be logged, got %v", logged)
+	}
+}
@@ -0,0 +1,254 @@
+// Package menu implements an on-device settings menu operated with two
+// buttons. Menus are tables of Items; each item is a submenu, an integer
+// value with its range, or an action.
+//
+// While browsing, button 1 moves to the next entry, button 2 opens it and a
+// long press on button 1 goes back. While a value is edited, button 1
+// decreases and button 2 increases it, held buttons repeat, and a chord of
+// both buttons saves it. A chord while browsing closes the menu.
+package menu
+
+import (
+	"strconv"
+
+	"pico_co2/internal/button"
+	"pico_co2/internal/display"
+	"pico_co2/internal/types"
+)
+
+// Item is one entry of a menu. Exactly one of Items, Set or Action is set.
+type Item struct {
+	Label string
+
+	// Items makes the entry a submenu.
+	Items []Item
+
+	// Get and Set make the entry an integer value in [Min, Max] changed in
+	// Step increments. Set validates and applies the value; its error is
+	// shown and the value stays in edit mode.
+	Get       func() int
+	Set       func(v int) error
+	Min, Max  int
+	Step      int
+	Choices   []string         // names of the values Min, Min+1, ..., optional
+	Format    func(int) string // optional, takes precedence over Choices
+	Unit      string           // appended to the formatted value
+	Wrap      bool             // step from Max to Min and back
+	Immediate bool             // call Set after every step, e.g. for brightness
+
+	// Action makes the entry a command run when it is opened.
+	Action func() error
+}
+
+// FormatValue formats v as shown in the menu.
+func (it *Item) FormatValue(v int) string {
+	var s string
+	switch {
+	case it.Format != nil:
+		s = it.Format(v)
+	case v-it.Min >= 0 && v-it.Min < len(it.Choices):
+		s = it.Choices[v-it.Min]
+	default:
+		s = strconv.Itoa(v)
+	}
+	if it.Unit != "" {
+		s += " " + it.Unit
+	}
+	return s
+}
+
+// Choice returns an item choosing one of choices, stored as its index.
+func Choice(label string, choices []string, get func() int, set func(int) error) Item {
+	return Item{
+		Label:   label,
+		Get:     get,
+		Set:     set,
+		Max:     len(choices) - 1,
+		Step:    1,
+		Choices: choices,
+		Wrap:    true,
+	}
+}
+
+// Toggle returns an off/on item.
+func Toggle(label string, get func() bool, set func(bool) error) Item {
+	return Choice(label, []string{"off", "on"},
+		func() int {
+			if get() {
+				return 1
+			}
+			return 0
+		},
+		func(v int) error { return set(v == 1) },
+	)
+}
+
+type level struct {
+	label string
+	items []Item
+	pos   int
+}
+
+// Menu is the navigation state.
+type Menu struct {
+	title   string
+	root    []Item
+	stack   []level // open submenus, the root first; empty when closed
+	editing bool
+	value   int
+	message string
+}
+
+func New(title string, items []Item) *Menu {
+	return &Menu{title: title, root: items, stack: make([]level, 0, 4)}
+}
+
+// Open shows the top level of the menu.
+func (m *Menu) Open() {
+	m.stack = append(m.stack[:0], level{label: m.title, items: m.root})
+	m.editing, m.message = false, ""
+}
+
+// Close leaves the menu, discarding an unsaved value.
+func (m *Menu) Close() {
+	m.stack = m.stack[:0]
+	m.editing, m.message = false, ""
+}
+
+func (m *Menu) IsOpen() bool {
+	return len(m.stack) > 0
+}
+
+// SetItems replaces the top-level items and closes the menu.
+func (m *Menu) SetItems(items []Item) {
+	m.root = items
+	m.Close()
+}
+
+func (m *Menu) current() (*level, *Item) {
+	l := &m.stack[len(m.stack)-1]
+	if len(l.items) == 0 {
+		return l, nil
+	}
+	return l, &l.items[l.pos]
+}
+
+// Handle applies a gesture to the open menu.
+func (m *Menu) Handle(g button.GestureEvent) {
+	if !m.IsOpen() {
+		return
+	}
+	if m.editing {
+		m.edit(g)
+		return
+	}
+
+	l, it := m.current()
+	switch {
+	case g.Gesture == button.Chord:
+		m.Close()
+	case g.Gesture == button.LongPress && g.Button == 1:
+		m.back()
+	case g.Gesture != button.ShortPress:
+	case g.Button == 1 && len(l.items) > 0:
+		l.pos = (l.pos + 1) % len(l.items)
+		m.message = ""
+	case g.Button == 2 && it != nil:
+		m.enter(it)
+	}
+}
+
+func (m *Menu) back() {
+	m.message = ""
+	m.stack = m.stack[:len(m.stack)-1]
+}
+
+func (m *Menu) enter(it *Item) {
+	m.message = ""
+	switch {
+	case it.Items != nil:
+		m.stack = append(m.stack, level{label: it.Label, items: it.Items})
+	case it.Set != nil:
+		m.editing = true
+		m.value = min(max(it.Get(), it.Min), it.Max)
+	case it.Action != nil:
+		m.message = result(it.Action(), "done")
+	}
+}
+
+func (m *Menu) edit(g button.GestureEvent) {
+	_, it := m.current()
+	switch g.Gesture {
+	case button.Chord:
+		if err := it.Set(m.value); err != nil {
+			m.message = err.Error()
+			return
+		}
+		m.editing = false
+		m.message = "saved"
+		return
+	case button.DoublePress:
+		return
+	}
+
+	step := max(it.Step, 1)
+	if g.Button == 1 {
+		step = -step
+	}
+	v := m.value + step
+	switch {
+	case v > it.Max && it.Wrap:
+		v = it.Min
+	case v < it.Min && it.Wrap:
+		v = it.Max
+	}
+	m.value = min(max(v, it.Min), it.Max)
+	m.message = ""
+	if it.Immediate {
+		m.message = result(it.Set(m.value), "")
+	}
+}
+
+func result(err error, ok string) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ok
+}
+
+// Render draws the menu; it has the signature of the display render
+// functions.
+func (m *Menu) Render(renderer display.Renderer, _ *types.Readings) {
+	display.RenderMenu(renderer, m.View())
+}
+
+// View returns what the display shows for the current state.
+func (m *Menu) View() display.MenuView {
+	if !m.IsOpen() {
+		return display.MenuView{}
+	}
+
+	l, it := m.current()
+	v := display.MenuView{
+		Title:   l.label,
+		Index:   l.pos + 1,
+		Count:   len(l.items),
+		Editing: m.editing,
+		Message: m.message,
+	}
+	if it == nil {
+		v.Label = "(empty)"
+		return v
+	}
+
+	v.Label = it.Label
+	switch {
+	case m.editing:
+		v.Value = it.FormatValue(m.value)
+	case it.Items != nil:
+		v.Value = ">"
+	case it.Get != nil:
+		v.Value = it.FormatValue(it.Get())
+	}
+	return v
+}
@@ -0,0 +1,98 @@
+package menu
+
+import (
+	"errors"
+	"testing"
+
+	"pico_co2/internal/button"
+)
+
+var (
+	next  = button.GestureEvent{Gesture: button.ShortPress, Button: 1}
+	open  = button.GestureEvent{Gesture: button.ShortPress, Button: 2}
+	back  = button.GestureEvent{Gesture: button.LongPress, Button: 1}
+	chord = button.GestureEvent{Gesture: button.Chord}
+	up    = open
+	down  = next
+)
+
+func TestMenuEditValue(t *testing.T) {
+	level := 10
+	m := New("Settings", []Item{
+		{Label: "Display", Items: []Item{{
+			Label: "Level",
+			Get:   func() int { return level },
+			Set: func(v int) error {
+				if v == 15 {
+					return errors.New("not 15")
+				}
+				level = v
+				return nil
+			},
+			Max:  20,
+			Step: 5,
+		}}},
+	})
+	m.Open()
+
+	m.Handle(open)
+	if v := m.View(); v.Title != "Display" || v.Label != "Level" || v.Value != "10" {
+		t.Fatalf("Expected Display/Level 10, got %+v", v)
+	}
+
+	m.Handle(open)
+	m.Handle(up)
+	m.Handle(chord)
+	if v := m.View(); level != 10 || !v.Editing || v.Message != "not 15" {
+		t.Errorf("Expected the invalid value to stay in edit mode, got %d %+v", level, v)
+	}
+
+	m.Handle(up)
+	m.Handle(up) // clamped at Max
+	m.Handle(chord)
+	if v := m.View(); level != 20 || v.Editing || v.Message != "saved" {
+		t.Errorf("Expected 20 to be saved, got %d %+v", level, v)
+	}
+
+	m.Handle(back)
+	m.Handle(back)
+	if m.IsOpen() {
+		t.Error("Expected back from the top level to close the menu")
+	}
+}
+
+func TestMenuChoiceWrapsAndActions(t *testing.T) {
+	unit, ran := 0, 0
+	m := New("Settings", []Item{
+		Choice("Unit", []string{"C", "F"}, func() int { return unit }, func(v int) error {
+			unit = v
+			return nil
+		}),
+		{Label: "Reset", Action: func() error {
+			ran++
+			return nil
+		}},
+	})
+	m.Open()
+
+	if v := m.View(); v.Value != "C" || v.Index != 1 || v.Count != 2 {
+		t.Errorf("Expected Unit C at 1/2, got %+v", v)
+	}
+	m.Handle(open)
+	m.Handle(down) // wraps from C to F
+	m.Handle(chord)
+	if unit != 1 {
+		t.Errorf("Expected F, got %d", unit)
+	}
+
+	m.Handle(next)
+	m.Handle(open)
+	if v := m.View(); ran != 1 || v.Message != "done" {
+		t.Errorf("Expected the action to run once, got %d %+v", ran, v)
+	}
+
+	m.Handle(chord)
+	if m.IsOpen() {
+		t.Error("Expected a chord to close the menu")
+	}
+}
@@ -0,0 +1,37 @@
+package sensor
+
+import (
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/aht20"
+)
+
+// AHT20 provides temperature and humidity.
+type AHT20 struct {
+	dev aht20.Device
+}
+
+func NewAHT20(bus drivers.I2C, clock hal.Clock) Sensor {
+	return &AHT20{dev: aht20.New(bus)}
+}
+
+func (s *AHT20) Name() string { return "aht20" }
+
+func (s *AHT20) Init() error {
+	s.dev.Reset()
+	s.dev.Configure()
+	return nil
+}
+
+func (s *AHT20) Read(raw *types.RawReadings) error {
+	if err := s.dev.Read(); err != nil {
+		return err
+	}
+
+	raw.Temperature = s.dev.Celsius()
+	raw.Humidity = s.dev.RelHumidity()
+	raw.Valid |= types.Temperature | types.Humidity
+	return nil
+}
@@ -0,0 +1,81 @@
+package sensor
+
+import (
+	"fmt"
+	"io"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+	"pico_co2/pkg/ens160"
+
+	"tinygo.org/x/drivers"
+)
+
+// ENS160 is the metal-oxide gas sensor providing TVOC, eCO2 and AQI. It must
+// be listed after a temperature/humidity sensor, whose values are used for
+// compensation.
+type ENS160 struct {
+	dev *ens160.Device
+}
+
+func NewENS160(bus drivers.I2C, clock hal.Clock) Sensor {
+	return &ENS160{dev: ens160.New(bus, ens160.DefaultAddress)}
+}
+
+func (s *ENS160) Name() string { return "ens160" }
+
+func (s *ENS160) Init() error {
+	return s.dev.Configure()
+}
+
+// Identity returns the firmware version read by Init.
+func (s *ENS160) Identity() string {
+	return "firmware " + s.dev.Firmware().String()
+}
+
+// Diagnose prints the compensation the sensor applies and the raw
+// resistances of its hot plates.
+func (s *ENS160) Diagnose(w io.Writer) error {
+	t, rh, err := s.dev.ReadCompensation()
+	if err != nil {
+		return err
+	}
+	hp0, hp3, err := s.dev.ReadResistances()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "compensation %.2f C %.1f %%\n", float32(t)/1000, float32(rh)/1000)
+	fmt.Fprintf(w, "resistance   hp0 %.0f ohm, hp3 %.0f ohm\n", hp0, hp3)
+	return nil
+}
+
+func (s *ENS160) Read(raw *types.RawReadings) error {
+	if raw.Valid.Has(types.Temperature | types.Humidity) {
+		err := s.dev.SetEnvDataMilli(
+			int32(raw.Temperature*1000),
+			int32(raw.Humidity*1000),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.dev.Update(drivers.Concentration); err != nil {
+		return err
+	}
+
+	if s.dev.Validity() == ens160.ValidityInvalidOutput {
+		return nil
+	}
+
+	raw.TVOC = s.dev.TVOC()
+	raw.ECO2 = s.dev.ECO2()
+	raw.Valid |= types.TVOC | types.ECO2
+
+	// The sensor reports UBA 1–5, the status package counts from zero.
+	if aqi := s.dev.AQI(); aqi >= 1 && aqi <= 5 {
+		raw.AQI = aqi - 1
+		raw.Valid |= types.AQI
+	}
+	return nil
+}
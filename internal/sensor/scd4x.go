@@ -0,0 +1,339 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+	"pico_co2/pkg/scd4x"
+
+	"tinygo.org/x/drivers"
+)
+
+// SCD4x is the NDIR CO2 sensor. It measures every 5 s in the Periodic mode
+// and every 30 s in the LowPower mode. The SingleShot and PowerDown modes,
+// which the SCD40 lacks, measure on demand. SingleShot keeps the sensor
+// idle in between; PowerDown powers it down, which saves the idle current
+// but doubles the shots, as the first one after a wake-up is discarded.
+// It only pays off for long sensor intervals.
+//
+// Its temperature and humidity are raised by its own heat, so they only
+// fill in for a missing temperature/humidity sensor listed before it.
+type SCD4x struct {
+	dev      *scd4x.Device
+	clock    hal.Clock
+	mode     Mode
+	measured bool      // the device holds a measurement
+	shotAt   time.Time // start of the pending single shot, zero if none
+	discard  bool      // the pending shot is the first after a wake-up
+	asleep   bool      // powered down in the PowerDown mode
+	pressure uint16    // hPa, 0 while compensating by altitude
+}
+
+func NewSCD4x(bus drivers.I2C, clock hal.Clock) Sensor {
+	dev := scd4x.New(bus, scd4x.DefaultAddress)
+	dev.Sleep = clock.Sleep
+	return &SCD4x{dev: dev, clock: clock}
+}
+
+func (s *SCD4x) Name() string { return "scd4x" }
+
+func (s *SCD4x) Init() error {
+	s.clock.Sleep(1500 * time.Millisecond)
+	if err := s.dev.Configure(); err != nil {
+		// A sensor left powered down, also across a reset of the board,
+		// only answers after a wake-up.
+		if s.dev.WakeUp() != nil {
+			return err
+		}
+		if err := s.dev.Configure(); err != nil {
+			return err
+		}
+	}
+
+	s.clock.Sleep(1500 * time.Millisecond)
+
+	s.measured = false
+	s.shotAt = time.Time{}
+	s.discard = false
+	s.asleep = false
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	s.clock.Sleep(1500 * time.Millisecond)
+
+	// The ambient pressure is not kept across a re-init. A powered down
+	// sensor gets it at the wake-up.
+	if s.pressure != 0 && !s.asleep {
+		return s.dev.SetAmbientPressure(s.pressure)
+	}
+	return nil
+}
+
+func (s *SCD4x) Read(raw *types.RawReadings) error {
+	if s.mode >= SingleShot {
+		// Read without Prepare: measure now and wait for it.
+		for {
+			d, err := s.Prepare()
+			if err != nil {
+				return err
+			}
+			if d == 0 {
+				break
+			}
+			s.clock.Sleep(d)
+		}
+		s.shotAt = time.Time{}
+	}
+
+	err := s.dev.Update(drivers.Concentration | drivers.Temperature | drivers.Humidity)
+	if s.mode == PowerDown && (err == nil || errors.Is(err, scd4x.ErrNotReady)) {
+		if err := s.powerDown(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case errors.Is(err, scd4x.ErrNotReady):
+		// Between two measurements the last one is still current.
+		if !s.measured {
+			return nil
+		}
+	case err != nil:
+		return err
+	default:
+		s.measured = true
+	}
+
+	raw.CO2 = s.dev.CO2()
+	raw.Valid |= types.CO2
+	if !raw.Valid.Has(types.Temperature) {
+		raw.Temperature = float32(s.dev.Temperature()) / 1000
+		raw.Valid |= types.Temperature
+	}
+	if !raw.Valid.Has(types.Humidity) {
+		raw.Humidity = float32(s.dev.Humidity()) / 1000
+		raw.Valid |= types.Humidity
+	}
+	return nil
+}
+
+// SetMode stops measuring and continues in mode m.
+func (s *SCD4x) SetMode(m Mode) error {
+	if m > PowerDown {
+		return fmt.Errorf("scd4x %s: %w", m, ErrModeUnsupported)
+	}
+	if m == s.mode {
+		return nil
+	}
+	if err := s.stop(); err != nil {
+		return err
+	}
+	if m >= SingleShot && s.mode < SingleShot {
+		if err := s.checkSingleShot(m); err != nil {
+			// Keep measuring in the current mode.
+			if startErr := s.start(); startErr != nil {
+				return startErr
+			}
+			return err
+		}
+	}
+	s.mode = m
+	return s.start()
+}
+
+// checkSingleShot returns ErrModeUnsupported for mode m on an SCD40.
+// Sensors whose firmware cannot report the variant are probed with a
+// single shot, which then becomes the pending one.
+func (s *SCD4x) checkSingleShot(m Mode) error {
+	unsupported := fmt.Errorf("scd4x %s: %w", m, ErrModeUnsupported)
+	v, err := s.dev.SensorVariant()
+	switch {
+	case err == nil && v == scd4x.SCD40:
+		return unsupported
+	case err == nil:
+		return nil
+	}
+	if err := s.dev.MeasureSingleShot(); err != nil {
+		return unsupported
+	}
+	s.shotAt = s.clock.Now()
+	return nil
+}
+
+// Prepare starts a single shot, unless one is already running, and returns
+// how long it still takes. In the PowerDown mode it wakes the sensor
+// first, and once the first shot after the wake-up is done it drops it
+// and starts the next one.
+func (s *SCD4x) Prepare() (time.Duration, error) {
+	if s.mode < SingleShot {
+		return 0, nil
+	}
+	if s.discard && !s.shotAt.IsZero() && s.clock.Since(s.shotAt) >= scd4x.SingleShotTime {
+		s.shotAt = time.Time{}
+		s.discard = false
+		if err := s.dev.ReadMeasurement(); err != nil {
+			return 0, err
+		}
+	}
+	if s.shotAt.IsZero() {
+		if err := s.wake(); err != nil {
+			return 0, err
+		}
+		if err := s.dev.MeasureSingleShot(); err != nil {
+			return 0, err
+		}
+		s.shotAt = s.clock.Now()
+	}
+	return max(scd4x.SingleShotTime-s.clock.Since(s.shotAt), 0), nil
+}
+
+// settle waits for the pending single shot to complete. The shot is not
+// read, so the next one is kept even after a wake-up.
+func (s *SCD4x) settle() {
+	if s.shotAt.IsZero() {
+		return
+	}
+	if d := scd4x.SingleShotTime - s.clock.Since(s.shotAt); d > 0 {
+		s.clock.Sleep(d)
+	}
+	s.shotAt = time.Time{}
+	s.discard = false
+}
+
+// wake powers the sensor up if it is down. Its first shot is discarded and
+// the ambient pressure, which is not persisted, is set again.
+func (s *SCD4x) wake() error {
+	if !s.asleep {
+		return nil
+	}
+	if err := s.dev.WakeUp(); err != nil {
+		return err
+	}
+	s.asleep = false
+	s.discard = true
+	if s.pressure != 0 {
+		return s.dev.SetAmbientPressure(s.pressure)
+	}
+	return nil
+}
+
+// powerDown powers the idle sensor down until the next wake.
+func (s *SCD4x) powerDown() error {
+	if err := s.dev.PowerDown(); err != nil {
+		return err
+	}
+	s.asleep = true
+	return nil
+}
+
+// start measures in the current mode; single shots are started by Prepare.
+// In the PowerDown mode a shot left pending by checkSingleShot is read
+// before the sensor powers down.
+func (s *SCD4x) start() error {
+	s.discard = false // the sensor is awake since stop
+	switch s.mode {
+	case LowPower:
+		return s.dev.StartLowPowerPeriodicMeasurement()
+	case SingleShot:
+		return nil
+	case PowerDown:
+		if s.shotAt.IsZero() {
+			return s.powerDown()
+		}
+		return nil
+	}
+	return s.dev.StartPeriodicMeasurement()
+}
+
+// stop brings the sensor to idle, where it accepts settings.
+func (s *SCD4x) stop() error {
+	switch s.mode {
+	case SingleShot:
+		s.settle()
+		return nil
+	case PowerDown:
+		s.settle()
+		return s.wake()
+	}
+	return s.dev.StopPeriodicMeasurement()
+}
+
+// Calibrate runs a forced recalibration to reference ppm. The sensor must
+// have been measuring in fresh air of that concentration for a few minutes.
+func (s *SCD4x) Calibrate(q types.Quantity, reference float32) error {
+	if q != types.CO2 {
+		return fmt.Errorf("scd4x %s: %w", q, ErrNotCalibratable)
+	}
+	if reference < 400 || reference > 2000 {
+		return fmt.Errorf("reference %.0f ppm out of range 400-2000", reference)
+	}
+
+	return s.idle(func() error {
+		_, err := s.dev.ForcedRecalibration(uint16(reference))
+		return err
+	})
+}
+
+// SetAutoCalibration turns automatic self-calibration on or off and
+// persists the setting.
+func (s *SCD4x) SetAutoCalibration(on bool) error {
+	return s.idle(func() error {
+		if err := s.dev.SetAutomaticSelfCalibration(on); err != nil {
+			return err
+		}
+		return s.dev.PersistSettings()
+	})
+}
+
+// SetAltitude sets the altitude used for pressure compensation and
+// persists it.
+func (s *SCD4x) SetAltitude(metres uint16) error {
+	if metres > 3000 {
+		return fmt.Errorf("altitude %d m out of range 0-3000", metres)
+	}
+	return s.idle(func() error {
+		if err := s.dev.SetSensorAltitude(metres); err != nil {
+			return err
+		}
+		return s.dev.PersistSettings()
+	})
+}
+
+// SetPressure sets the ambient pressure, which overrides the altitude until
+// it is set to 0. It is not persisted and may change while measuring.
+func (s *SCD4x) SetPressure(hPa uint16) error {
+	if s.asleep && hPa != 0 {
+		s.pressure = hPa // set at the wake-up
+		return nil
+	}
+	if hPa == 0 {
+		// A re-init drops the pressure and reloads the altitude.
+		s.pressure = 0
+		return s.idle(s.dev.Reinit)
+	}
+	if hPa < 700 || hPa > 1200 {
+		return fmt.Errorf("pressure %d hPa out of range 700-1200", hPa)
+	}
+	if err := s.dev.SetAmbientPressure(hPa); err != nil {
+		return err
+	}
+	s.pressure = hPa
+	return nil
+}
+
+// idle stops the periodic measurement, runs fn and measures again, also
+// when fn failed. Most settings are only accepted while the sensor is idle.
+func (s *SCD4x) idle(fn func() error) error {
+	if err := s.stop(); err != nil {
+		return err
+	}
+
+	err := fn()
+	if startErr := s.start(); err == nil {
+		err = startErr
+	}
+	return err
+}
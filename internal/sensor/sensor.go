@@ -0,0 +1,169 @@
+// Package sensor defines the interface implemented by every measurement
+// board and the registry used to build the sensor set from configuration.
+package sensor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"pico_co2/internal/hal"
+	"pico_co2/internal/types"
+
+	"tinygo.org/x/drivers"
+)
+
+// Sensor is a single measurement device on the I2C bus.
+type Sensor interface {
+	// Name returns the registry name of the sensor.
+	Name() string
+	// Init brings the device into measurement mode.
+	Init() error
+	// Read fills the quantities the sensor provides into raw and marks them
+	// in raw.Valid.
+	Read(raw *types.RawReadings) error
+}
+
+// Identifier is implemented by sensors that report their part or firmware
+// version after Init, for the startup diagnostics.
+type Identifier interface {
+	Identity() string
+}
+
+// Diagnoser is implemented by sensors with raw values beyond their
+// readings, such as the compensation they apply.
+type Diagnoser interface {
+	// Diagnose reads the raw values and prints them to w.
+	Diagnose(w io.Writer) error
+}
+
+// ErrNotCalibratable is returned by Calibrator for quantities the sensor
+// cannot calibrate.
+var ErrNotCalibratable = errors.New("quantity cannot be calibrated")
+
+// Calibrator is implemented by sensors that support a forced recalibration
+// against a known reference value.
+type Calibrator interface {
+	// Calibrate corrects the reading of q to match reference.
+	Calibrate(q types.Quantity, reference float32) error
+}
+
+// CO2Compensator is implemented by CO2 sensors with automatic
+// self-calibration and pressure compensation.
+type CO2Compensator interface {
+	// SetAutoCalibration turns automatic self-calibration on or off.
+	SetAutoCalibration(on bool) error
+	// SetAltitude sets the altitude in metres above sea level.
+	SetAltitude(metres uint16) error
+	// SetPressure sets the ambient pressure in hPa, overriding the
+	// altitude; 0 compensates by altitude again.
+	SetPressure(hPa uint16) error
+}
+
+// Mode is how a sensor acquires its readings.
+type Mode uint8
+
+const (
+	Periodic   Mode = iota // measures continuously at the sensor's rate
+	LowPower               // measures continuously at a lower rate
+	SingleShot             // measures when asked, idle in between
+	PowerDown              // measures when asked, powered down in between
+)
+
+var ModeStrings = [...]string{
+	"periodic",
+	"low-power",
+	"single-shot",
+	"power-down",
+}
+
+func (m Mode) String() string {
+	if m > PowerDown {
+		return "unknown"
+	}
+	return ModeStrings[m]
+}
+
+// ParseMode returns the mode named s.
+func ParseMode(s string) (Mode, bool) {
+	for i, n := range ModeStrings {
+		if n == s {
+			return Mode(i), true
+		}
+	}
+	return 0, false
+}
+
+func (m Mode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+func (m *Mode) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	mode, ok := ParseMode(name)
+	if !ok {
+		return fmt.Errorf("unknown mode %q", name)
+	}
+	*m = mode
+	return nil
+}
+
+// ErrModeUnsupported is returned by ModeSetter for modes the sensor does
+// not have.
+var ErrModeUnsupported = errors.New("mode not supported")
+
+// ModeSetter is implemented by sensors with several acquisition modes.
+type ModeSetter interface {
+	SetMode(m Mode) error
+}
+
+// Preparer is implemented by sensors that can measure on demand. Prepare
+// starts a measurement and returns how long it takes. A measurement may
+// take several steps, so Prepare is called again until it returns 0,
+// after which Read gets the result. It also returns 0 if the sensor
+// measures on its own.
+type Preparer interface {
+	Prepare() (time.Duration, error)
+}
+
+// Factory creates a sensor attached to bus.
+type Factory func(bus drivers.I2C, clock hal.Clock) Sensor
+
+type Driver struct {
+	Name string
+	New  Factory
+}
+
+// Registry lists all supported sensors.
+var Registry = []Driver{
+	{"aht20", NewAHT20},
+	{"ens160", NewENS160},
+	{"scd4x", NewSCD4x},
+}
+
+// Build creates the sensors with the given names in the given order.
+func Build(names []string, bus drivers.I2C, clock hal.Clock) ([]Sensor, error) {
+	sensors := make([]Sensor, 0, len(names))
+	for _, name := range names {
+		driver, ok := lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown sensor %q", name)
+		}
+		sensors = append(sensors, driver.New(bus, clock))
+	}
+	return sensors, nil
+}
+
+func lookup(name string) (Driver, bool) {
+	for _, d := range Registry {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Driver{}, false
+}
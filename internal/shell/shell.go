@@ -0,0 +1,145 @@
+// Package shell implements a line-based command shell over a serial console.
+//
+// The shell never blocks: Poll consumes whatever input is available and runs
+// every complete line, so it can be driven from the main loop. It works on
+// any io.Reader/io.Writer, which makes commands testable on the host.
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxLineLength is the longest accepted input line.
+const MaxLineLength = 80
+
+// ErrUsage makes the shell print the usage of the command that returned it.
+var ErrUsage = errors.New("invalid arguments")
+
+// Command is a shell command. Args are the words after the command name.
+type Command struct {
+	Name    string
+	Usage   string // argument synopsis, e.g. "<quantity>"
+	Help    string
+	MinArgs int
+	MaxArgs int // negative for no limit
+	Run     func(w io.Writer, args []string) error
+}
+
+type Shell struct {
+	in       io.Reader
+	out      io.Writer
+	commands []Command
+	line     []byte
+	overflow bool
+	buf      [32]byte
+}
+
+// New returns a shell reading commands from in and writing to out. A
+// "help" command is added to commands.
+func New(in io.Reader, out io.Writer, commands []Command) *Shell {
+	s := &Shell{
+		in:   in,
+		out:  out,
+		line: make([]byte, 0, MaxLineLength),
+	}
+	s.commands = append([]Command{{
+		Name:    "help",
+		Usage:   "[command]",
+		Help:    "list commands or show the usage of one",
+		MaxArgs: 1,
+		Run:     s.help,
+	}}, commands...)
+	return s
+}
+
+// Poll reads the available input and executes every complete line. The
+// reader must return immediately when no input is pending.
+func (s *Shell) Poll() {
+	for {
+		n, err := s.in.Read(s.buf[:])
+		for _, c := range s.buf[:n] {
+			s.feed(c)
+		}
+		if err != nil || n < len(s.buf) {
+			return
+		}
+	}
+}
+
+func (s *Shell) feed(c byte) {
+	switch c {
+	case '\r', '\n':
+		if s.overflow {
+			fmt.Fprintf(s.out, "error: line longer than %d characters\n", MaxLineLength)
+		} else if len(s.line) > 0 {
+			s.Exec(string(s.line))
+		}
+		s.line = s.line[:0]
+		s.overflow = false
+	case '\b', 0x7F:
+		if len(s.line) > 0 {
+			s.line = s.line[:len(s.line)-1]
+		}
+	default:
+		if len(s.line) == MaxLineLength {
+			s.overflow = true
+			return
+		}
+		s.line = append(s.line, c)
+	}
+}
+
+// Exec runs a single command line.
+func (s *Shell) Exec(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	cmd := s.find(fields[0])
+	if cmd == nil {
+		fmt.Fprintf(s.out, "unknown command %q, try help\n", fields[0])
+		return
+	}
+
+	args := fields[1:]
+	err := ErrUsage
+	if len(args) >= cmd.MinArgs && (cmd.MaxArgs < 0 || len(args) <= cmd.MaxArgs) {
+		err = cmd.Run(s.out, args)
+	}
+
+	switch {
+	case errors.Is(err, ErrUsage):
+		fmt.Fprintf(s.out, "usage: %s %s\n", cmd.Name, cmd.Usage)
+	case err != nil:
+		fmt.Fprintf(s.out, "error: %v\n", err)
+	}
+}
+
+func (s *Shell) find(name string) *Command {
+	for i := range s.commands {
+		if s.commands[i].Name == name {
+			return &s.commands[i]
+		}
+	}
+	return nil
+}
+
+func (s *Shell) help(w io.Writer, args []string) error {
+	if len(args) == 1 {
+		cmd := s.find(args[0])
+		if cmd == nil {
+			return fmt.Errorf("unknown command %q", args[0])
+		}
+		fmt.Fprintf(w, "%s %s\n  %s\n", cmd.Name, cmd.Usage, cmd.Help)
+		return nil
+	}
+
+	for _, cmd := range s.commands {
+		fmt.Fprintf(w, "%-28s %s\n", cmd.Name+" "+cmd.Usage, cmd.Help)
+	}
+	return nil
+}
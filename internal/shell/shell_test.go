@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestShell(in string) (*Shell, *bytes.Buffer, *[]string) {
+	var got []string
+	out := &bytes.Buffer{}
+	s := New(strings.NewReader(in), out, []Command{{
+		Name:    "echo",
+		Usage:   "<word>...",
+		Help:    "print the arguments",
+		MinArgs: 1,
+		MaxArgs: -1,
+		Run: func(w io.Writer, args []string) error {
+			got = append(got, strings.Join(args, " "))
+			return nil
+		},
+	}})
+	return s, out, &got
+}
+
+func TestShellRunsCompleteLines(t *testing.T) {
+	s, _, got := newTestShell("echo a  b\r\nech\x7fho c\necho d")
+	s.Poll()
+
+	want := []string{"a b", "c"}
+	if strings.Join(*got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, *got)
+	}
+	if string(s.line) != "echo d" {
+		t.Errorf("Expected incomplete line to be kept, got %q", s.line)
+	}
+}
+
+func TestShellValidatesArguments(t *testing.T) {
+	s, out, got := newTestShell("")
+
+	s.Exec("echo")
+	if len(*got) != 0 || !strings.Contains(out.String(), "usage: echo <word>...") {
+		t.Errorf("Expected usage, got %q", out.String())
+	}
+
+	out.Reset()
+	s.Exec("nope")
+	if !strings.Contains(out.String(), `unknown command "nope"`) {
+		t.Errorf("Expected unknown command, got %q", out.String())
+	}
+
+	out.Reset()
+	s.Exec("help")
+	if !strings.Contains(out.String(), "print the arguments") {
+		t.Errorf("Expected help to list echo, got %q", out.String())
+	}
+}
+
+func TestShellRejectsLongLines(t *testing.T) {
+	s, out, got := newTestShell("echo " + strings.Repeat("x", MaxLineLength) + "\necho ok\n")
+	s.Poll()
+
+	if len(*got) != 1 || (*got)[0] != "ok" {
+		t.Errorf("Expected only the short line to run, got %q", *got)
+	}
+	if !strings.Contains(out.String(), "line longer than") {
+		t.Errorf("Expected overflow error, got %q", out.String())
+	}
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// BlockDevice is a flash-like device that must be erased before writing.
+// machine.Flash implements it on the RP2040.
+type BlockDevice interface {
+	ReadAt(p []byte, off int64) (n int, err error)
+	WriteAt(p []byte, off int64) (n int, err error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+const (
+	blockMagic      = "PKV1"
+	blockHeaderSize = 16
+	minSlotSize     = 4096
+)
+
+var (
+	ErrDeviceTooSmall = errors.New("store: block device too small")
+	ErrStoreFull      = errors.New("store: data does not fit into a slot")
+)
+
+// BlockStore keeps all keys in one record on a BlockDevice. Records are
+// written alternately into two slots with an increasing sequence number, so
+// a power loss during a write leaves the previous record intact.
+//
+// Slot layout (little endian):
+//
+//	magic [4]byte | seq uint32 | length uint32 | crc32 uint32 | entries
+//
+// where each entry is klen uint8 | key | vlen uint16 | value.
+type BlockStore struct {
+	dev      BlockDevice
+	slotSize int64
+	seq      uint32
+	active   int // slot holding the current record, -1 if none
+	data     map[string][]byte
+}
+
+// NewBlockStore loads the newest valid record from dev.
+func NewBlockStore(dev BlockDevice) (*BlockStore, error) {
+	ebs := dev.EraseBlockSize()
+	slotSize := (minSlotSize + ebs - 1) / ebs * ebs
+	if dev.Size() < 2*slotSize {
+		return nil, ErrDeviceTooSmall
+	}
+
+	s := &BlockStore{
+		dev:      dev,
+		slotSize: slotSize,
+		active:   -1,
+		data:     make(map[string][]byte),
+	}
+
+	for slot := 0; slot < 2; slot++ {
+		seq, payload, ok := s.readSlot(slot)
+		if !ok || (s.active >= 0 && seq <= s.seq) {
+			continue
+		}
+		data, err := decodeEntries(payload)
+		if err != nil {
+			continue
+		}
+		s.active, s.seq, s.data = slot, seq, data
+	}
+
+	return s, nil
+}
+
+func (s *BlockStore) Get(key string) ([]byte, error) {
+	v, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (s *BlockStore) Put(key string, value []byte) error {
+	if len(key) > 0xFF || len(value) > 0xFFFF {
+		return ErrStoreFull
+	}
+	old, existed := s.data[key]
+	s.data[key] = append([]byte(nil), value...)
+	if err := s.flush(); err != nil {
+		if existed {
+			s.data[key] = old
+		} else {
+			delete(s.data, key)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *BlockStore) Delete(key string) error {
+	old, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	delete(s.data, key)
+	if err := s.flush(); err != nil {
+		s.data[key] = old
+		return err
+	}
+	return nil
+}
+
+func (s *BlockStore) flush() error {
+	payload := encodeEntries(s.data)
+	if int64(blockHeaderSize+len(payload)) > s.slotSize {
+		return ErrStoreFull
+	}
+
+	// Pad to the write block size with the erased value.
+	wbs := s.dev.WriteBlockSize()
+	size := (int64(blockHeaderSize+len(payload)) + wbs - 1) / wbs * wbs
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = 0xFF
+	}
+
+	seq := s.seq + 1
+	copy(buf[0:4], blockMagic)
+	binary.LittleEndian.PutUint32(buf[4:8], seq)
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[12:16], crc32.ChecksumIEEE(payload))
+	copy(buf[blockHeaderSize:], payload)
+
+	slot := 0
+	if s.active == 0 {
+		slot = 1
+	}
+	off := int64(slot) * s.slotSize
+	ebs := s.dev.EraseBlockSize()
+	if err := s.dev.EraseBlocks(off/ebs, s.slotSize/ebs); err != nil {
+		return err
+	}
+	if _, err := s.dev.WriteAt(buf, off); err != nil {
+		return err
+	}
+
+	s.active, s.seq = slot, seq
+	return nil
+}
+
+func (s *BlockStore) readSlot(slot int) (seq uint32, payload []byte, ok bool) {
+	off := int64(slot) * s.slotSize
+	var hdr [blockHeaderSize]byte
+	if _, err := s.dev.ReadAt(hdr[:], off); err != nil {
+		return 0, nil, false
+	}
+	if string(hdr[0:4]) != blockMagic {
+		return 0, nil, false
+	}
+
+	seq = binary.LittleEndian.Uint32(hdr[4:8])
+	length := int64(binary.LittleEndian.Uint32(hdr[8:12]))
+	if length > s.slotSize-blockHeaderSize {
+		return 0, nil, false
+	}
+
+	payload = make([]byte, length)
+	if _, err := s.dev.ReadAt(payload, off+blockHeaderSize); err != nil {
+		return 0, nil, false
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[12:16]) {
+		return 0, nil, false
+	}
+	return seq, payload, true
+}
+
+func encodeEntries(data map[string][]byte) []byte {
+	var buf []byte
+	for k, v := range data {
+		buf = append(buf, byte(len(k)))
+		buf = append(buf, k...)
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(len(v)))
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+func decodeEntries(buf []byte) (map[string][]byte, error) {
+	errCorrupt := errors.New("store: corrupt record")
+	data := make(map[string][]byte)
+	for len(buf) > 0 {
+		klen := int(buf[0])
+		if len(buf) < 1+klen+2 {
+			return nil, errCorrupt
+		}
+		key := string(buf[1 : 1+klen])
+		buf = buf[1+klen:]
+		vlen := int(binary.LittleEndian.Uint16(buf))
+		buf = buf[2:]
+		if len(buf) < vlen {
+			return nil, errCorrupt
+		}
+		data[key] = append([]byte(nil), buf[:vlen]...)
+		buf = buf[vlen:]
+	}
+	return data, nil
+}
+
+// MemBlockDevice is an in-memory BlockDevice for tests and the simulator.
+type MemBlockDevice struct {
+	buf            []byte
+	writeBlockSize int64
+	eraseBlockSize int64
+	Erases         int
+}
+
+// NewMemBlockDevice returns an erased device of the given size.
+func NewMemBlockDevice(size, writeBlockSize, eraseBlockSize int64) *MemBlockDevice {
+	d := &MemBlockDevice{
+		buf:            make([]byte, size),
+		writeBlockSize: writeBlockSize,
+		eraseBlockSize: eraseBlockSize,
+	}
+	for i := range d.buf {
+		d.buf[i] = 0xFF
+	}
+	return d
+}
+
+func (d *MemBlockDevice) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(d.buf)) {
+		return 0, errors.New("read out of range")
+	}
+	return copy(p, d.buf[off:]), nil
+}
+
+func (d *MemBlockDevice) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(d.buf)) {
+		return 0, errors.New("write out of range")
+	}
+	// Flash can only clear bits.
+	for i, b := range p {
+		d.buf[off+int64(i)] &= b
+	}
+	return len(p), nil
+}
+
+func (d *MemBlockDevice) Size() int64           { return int64(len(d.buf)) }
+func (d *MemBlockDevice) WriteBlockSize() int64 { return d.writeBlockSize }
+func (d *MemBlockDevice) EraseBlockSize() int64 { return d.eraseBlockSize }
+
+func (d *MemBlockDevice) EraseBlocks(start, n int64) error {
+	from, to := start*d.eraseBlockSize, (start+n)*d.eraseBlockSize
+	if from < 0 || to > int64(len(d.buf)) {
+		return errors.New("erase out of range")
+	}
+	for i := from; i < to; i++ {
+		d.buf[i] = 0xFF
+	}
+	d.Erases++
+	return nil
+}
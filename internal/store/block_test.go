@@ -0,0 +1,55 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBlockStorePersistsAcrossReopen(t *testing.T) {
+	dev := NewMemBlockDevice(16*1024, 256, 4096)
+
+	s, err := NewBlockStore(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("config"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound on empty device, got %v", err)
+	}
+
+	for _, v := range []string{"one", "two", "three"} {
+		if err := s.Put("config", []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Put("other", []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewBlockStore(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := s.Get("config")
+	if err != nil || string(v) != "three" {
+		t.Errorf("Expected \"three\", got %q (%v)", v, err)
+	}
+	if v, _ := s.Get("other"); len(v) != 3 {
+		t.Errorf("Expected other key to survive, got %v", v)
+	}
+}
+
+func TestBlockStoreKeepsPreviousRecordOnCorruption(t *testing.T) {
+	dev := NewMemBlockDevice(16*1024, 256, 4096)
+	s, _ := NewBlockStore(dev)
+	s.Put("k", []byte("old"))
+	s.Put("k", []byte("new"))
+
+	// Corrupt the newest record, as if power was lost while writing it.
+	newest := int64(s.active) * s.slotSize
+	dev.buf[newest+blockHeaderSize] ^= 0xFF
+
+	s, _ = NewBlockStore(dev)
+	if v, _ := s.Get("k"); string(v) != "old" {
+		t.Errorf("Expected fallback to \"old\", got %q", v)
+	}
+}
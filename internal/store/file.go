@@ -0,0 +1,50 @@
+//go:build !tinygo
+
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps every key in its own file inside a directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates dir if needed and returns a store backed by it.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) Get(key string) ([]byte, error) {
+	v, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+func (f *FileStore) Put(key string, value []byte) error {
+	tmp := f.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, value, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(key))
+}
+
+func (f *FileStore) Delete(key string) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *FileStore) path(key string) string {
+	return filepath.Join(f.dir, filepath.Base(key))
+}
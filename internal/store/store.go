@@ -0,0 +1,43 @@
+// Package store provides a small key-value store used for persistent
+// configuration. It is backed by the RP2040 flash on the device and by
+// memory or files on the host.
+package store
+
+import "errors"
+
+// ErrNotFound is returned by Get for missing keys.
+var ErrNotFound = errors.New("store: key not found")
+
+// Store is a persistent key-value store.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+}
+
+// MemStore is a Store kept in memory.
+type MemStore struct {
+	data map[string][]byte
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{data: make(map[string][]byte)}
+}
+
+func (m *MemStore) Get(key string) ([]byte, error) {
+	v, ok := m.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (m *MemStore) Put(key string, value []byte) error {
+	m.data[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *MemStore) Delete(key string) error {
+	delete(m.data, key)
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package telemetry encodes readings as machine-readable records for logging
+// to a PC: CSV with a header, JSON Lines and InfluxDB line protocol.
+//
+// The records share the serial console with human-readable output, whose
+// lines all start with CommentPrefix. Parsers skip those lines; InfluxDB
+// already treats them as comments.
+package telemetry
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"pico_co2/internal/types"
+	"pico_co2/internal/types/status"
+)
+
+type Format uint8
+
+const (
+	FormatOff Format = iota
+	FormatCSV
+	FormatJSON
+	FormatInflux
+)
+
+var FormatStrings = [...]string{
+	"off",
+	"csv",
+	"jsonl",
+	"influx",
+}
+
+func (f Format) String() string {
+	if f > FormatInflux {
+		return "unknown"
+	}
+	return FormatStrings[f]
+}
+
+// ParseFormat returns the format with the given String name.
+func ParseFormat(name string) (Format, bool) {
+	for i, n := range FormatStrings {
+		if n == name {
+			return Format(i), true
+		}
+	}
+	return FormatOff, false
+}
+
+// Measurement is the InfluxDB measurement name.
+const Measurement = "pico_co2"
+
+// CommentPrefix starts every console line that is not a record.
+const CommentPrefix = "#"
+
+// Record is a single telemetry sample. Values of quantities missing from
+// Valid are not meaningful and are left out of the encoded record.
+type Record struct {
+	Device      string
+	Time        time.Time // RTC time
+	TimeTrusted bool      // the RTC was set from a trusted source
+	CO2         uint16
+	Temperature float32
+	Humidity    float32
+	TVOC        uint16
+	ECO2        uint16
+	AQI         uint8 // UBA index, 1-5
+	HeatIndexC  float32
+	CO2Index    status.CO2Index
+	HeatIndex   status.HeatIndex
+	AQIIndex    status.AQIIndex
+	Trend       status.CO2Trend
+	Valid       types.Quantity
+}
+
+// NewRecord builds a record from the latest readings.
+func NewRecord(device string, t time.Time, r *types.Readings) Record {
+	raw := r.Raw
+	rec := Record{
+		Device:      device,
+		Time:        t,
+		TimeTrusted: r.Time.Trusted,
+		CO2:         raw.CO2,
+		Temperature: raw.Temperature,
+		Humidity:    raw.Humidity,
+		TVOC:        raw.TVOC,
+		ECO2:        raw.ECO2,
+		AQI:         raw.AQI + 1,
+		CO2Index:    status.UnknownCO2,
+		HeatIndex:   status.UnknownHeatIndex,
+		AQIIndex:    status.UnknownAQI,
+		Trend:       r.Calculated.CO2Trend,
+		Valid:       raw.Valid,
+	}
+	if raw.Valid.Has(types.CO2) {
+		rec.CO2Index = status.ToCO2Index(raw.CO2)
+	}
+	if raw.Valid.Has(types.Temperature | types.Humidity) {
+		rec.HeatIndexC = status.HeatIndexVal(raw.Temperature, raw.Humidity)
+		rec.HeatIndex = status.GetHeatIndex(raw.Temperature, raw.Humidity)
+	}
+	if raw.Valid.Has(types.AQI) {
+		rec.AQIIndex = status.ToAQIIndex(raw.AQI)
+	}
+	return rec
+}
+
+// validNames lists the valid quantities, e.g. "co2 temperature".
+func (r Record) validNames() string {
+	var names []string
+	for _, q := range types.AllQuantities {
+		if r.Valid.Has(q) {
+			names = append(names, q.String())
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Encoder writes records in one format to w.
+type Encoder struct {
+	w           io.Writer
+	format      Format
+	wroteHeader bool
+}
+
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{w: w, format: format}
+}
+
+// WriteHeader writes the CSV header, e.g. for a PC that connected after
+// the first record. Other formats have no header.
+func (e *Encoder) WriteHeader() error {
+	if e.format != FormatCSV {
+		return nil
+	}
+	w := csv.NewWriter(e.w)
+	w.Write(csvHeader)
+	w.Flush()
+	e.wroteHeader = true
+	return w.Error()
+}
+
+// Encode writes a single record. The CSV header is written before the
+// first record unless WriteHeader was called.
+func (e *Encoder) Encode(r Record) error {
+	switch e.format {
+	case FormatCSV:
+		return e.encodeCSV(r)
+	case FormatJSON:
+		return e.encodeJSON(r)
+	case FormatInflux:
+		return e.encodeInflux(r)
+	}
+	return nil
+}
+
+var csvHeader = []string{
+	"device", "time", "time_trusted",
+	"co2", "temperature", "humidity", "tvoc", "eco2", "aqi", "heat_index_c",
+	"co2_index", "heat_index", "aqi_index", "co2_trend", "valid",
+}
+
+func (e *Encoder) encodeCSV(r Record) error {
+	if !e.wroteHeader {
+		if err := e.WriteHeader(); err != nil {
+			return err
+		}
+	}
+	w := csv.NewWriter(e.w)
+
+	// Invalid values are left empty.
+	value := func(q types.Quantity, s string) string {
+		if !r.Valid.Has(q) {
+			return ""
+		}
+		return s
+	}
+	heatIndex := ""
+	if r.Valid.Has(types.Temperature | types.Humidity) {
+		heatIndex = formatFloat(r.HeatIndexC)
+	}
+
+	w.Write([]string{
+		r.Device,
+		r.Time.UTC().Format(time.RFC3339),
+		strconv.FormatBool(r.TimeTrusted),
+		value(types.CO2, strconv.Itoa(int(r.CO2))),
+		value(types.Temperature, formatFloat(r.Temperature)),
+		value(types.Humidity, formatFloat(r.Humidity)),
+		value(types.TVOC, strconv.Itoa(int(r.TVOC))),
+		value(types.ECO2, strconv.Itoa(int(r.ECO2))),
+		value(types.AQI, strconv.Itoa(int(r.AQI))),
+		heatIndex,
+		r.CO2Index.String(),
+		r.HeatIndex.String(),
+		r.AQIIndex.String(),
+		r.Trend.String(),
+		r.validNames(),
+	})
+	w.Flush()
+	return w.Error()
+}
+
+// jsonRecord is the JSON Lines layout. Invalid values are null.
+type jsonRecord struct {
+	Device      string           `json:"device"`
+	Time        string           `json:"time"`
+	TimeTrusted bool             `json:"time_trusted"`
+	CO2         *uint16          `json:"co2"`
+	Temperature *float32         `json:"temperature"`
+	Humidity    *float32         `json:"humidity"`
+	TVOC        *uint16          `json:"tvoc"`
+	ECO2        *uint16          `json:"eco2"`
+	AQI         *uint8           `json:"aqi"`
+	HeatIndexC  *float32         `json:"heat_index_c"`
+	CO2Index    status.CO2Index  `json:"co2_index"`
+	HeatIndex   status.HeatIndex `json:"heat_index"`
+	AQIIndex    status.AQIIndex  `json:"aqi_index"`
+	Trend       status.CO2Trend  `json:"co2_trend"`
+	Valid       []string         `json:"valid"`
+}
+
+func (e *Encoder) encodeJSON(r Record) error {
+	j := jsonRecord{
+		Device:      r.Device,
+		Time:        r.Time.UTC().Format(time.RFC3339),
+		TimeTrusted: r.TimeTrusted,
+		CO2Index:    r.CO2Index,
+		HeatIndex:   r.HeatIndex,
+		AQIIndex:    r.AQIIndex,
+		Trend:       r.Trend,
+		Valid:       []string{},
+	}
+	if r.Valid.Has(types.CO2) {
+		j.CO2 = &r.CO2
+	}
+	if r.Valid.Has(types.Temperature) {
+		t := round2(r.Temperature)
+		j.Temperature = &t
+	}
+	if r.Valid.Has(types.Humidity) {
+		h := round2(r.Humidity)
+		j.Humidity = &h
+	}
+	if r.Valid.Has(types.TVOC) {
+		j.TVOC = &r.TVOC
+	}
+	if r.Valid.Has(types.ECO2) {
+		j.ECO2 = &r.ECO2
+	}
+	if r.Valid.Has(types.AQI) {
+		j.AQI = &r.AQI
+	}
+	if r.Valid.Has(types.Temperature | types.Humidity) {
+		hi := round2(r.HeatIndexC)
+		j.HeatIndexC = &hi
+	}
+	for _, q := range types.AllQuantities {
+		if r.Valid.Has(q) {
+			j.Valid = append(j.Valid, q.String())
+		}
+	}
+
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+func (e *Encoder) encodeInflux(r Record) error {
+	var b strings.Builder
+	b.WriteString(Measurement)
+	if r.Device != "" {
+		b.WriteString(",device=")
+		b.WriteString(escapeTag(r.Device))
+	}
+
+	// Invalid quantities are omitted from the field set.
+	sep := byte(' ')
+	field := func(key, value string) {
+		b.WriteByte(sep)
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(value)
+		sep = ','
+	}
+	if r.Valid.Has(types.CO2) {
+		field("co2", strconv.Itoa(int(r.CO2))+"i")
+	}
+	if r.Valid.Has(types.Temperature) {
+		field("temperature", formatFloat(r.Temperature))
+	}
+	if r.Valid.Has(types.Humidity) {
+		field("humidity", formatFloat(r.Humidity))
+	}
+	if r.Valid.Has(types.TVOC) {
+		field("tvoc", strconv.Itoa(int(r.TVOC))+"i")
+	}
+	if r.Valid.Has(types.ECO2) {
+		field("eco2", strconv.Itoa(int(r.ECO2))+"i")
+	}
+	if r.Valid.Has(types.AQI) {
+		field("aqi", strconv.Itoa(int(r.AQI))+"i")
+	}
+	if r.Valid.Has(types.Temperature | types.Humidity) {
+		field("heat_index_c", formatFloat(r.HeatIndexC))
+	}
+	field("co2_index", quoteField(r.CO2Index.String()))
+	field("heat_index", quoteField(r.HeatIndex.String()))
+	field("aqi_index", quoteField(r.AQIIndex.String()))
+	field("co2_trend", quoteField(r.Trend.String()))
+	field("time_trusted", strconv.FormatBool(r.TimeTrusted))
+	field("valid", quoteField(r.validNames()))
+
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(r.Time.UnixNano(), 10))
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func formatFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', 2, 32)
+}
+
+func round2(v float32) float32 {
+	f, _ := strconv.ParseFloat(formatFloat(v), 32)
+	return float32(f)
+}
+
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func escapeTag(s string) string {
+	return tagEscaper.Replace(s)
+}
+
+var fieldEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+
+func quoteField(s string) string {
+	return `"` + fieldEscaper.Replace(s) + `"`
+}
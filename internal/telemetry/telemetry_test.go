@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"pico_co2/internal/types"
+)
+
+func testRecord() Record {
+	r := types.InitReadings(10)
+	r.Add(types.RawReadings{
+		CO2:         850,
+		Temperature: 24.5,
+		Humidity:    40.25,
+		Valid:       types.CO2 | types.Temperature | types.Humidity,
+	})
+	r.Time.Trusted = true
+	return NewRecord("lab 1", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), r)
+}
+
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, FormatCSV)
+	e.Encode(testRecord())
+	e.Encode(testRecord())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and two records, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "device,time,time_trusted,co2,") {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	want := "lab 1,2026-10-16T12:00:00Z,true,850,24.50,40.25,,,,24.50,Fair,No heat,Unknown AQI,Unknown,co2 temperature humidity"
+	if lines[1] != want {
+		t.Errorf("Expected %q, got %q", want, lines[1])
+	}
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	NewEncoder(&buf, FormatJSON).Encode(testRecord())
+
+	want := `{"device":"lab 1","time":"2026-10-16T12:00:00Z","time_trusted":true,"co2":850,"temperature":24.5,"humidity":40.25,` +
+		`"tvoc":null,"eco2":null,"aqi":null,"heat_index_c":24.5,"co2_index":"Fair","heat_index":"No heat",` +
+		`"aqi_index":"Unknown AQI","co2_trend":"Unknown","valid":["co2","temperature","humidity"]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestEncodeInflux(t *testing.T) {
+	var buf bytes.Buffer
+	NewEncoder(&buf, FormatInflux).Encode(testRecord())
+
+	want := `pico_co2,device=lab\ 1 co2=850i,temperature=24.50,humidity=40.25,heat_index_c=24.50,` +
+		`co2_index="Fair",heat_index="No heat",aqi_index="Unknown AQI",co2_trend="Unknown",time_trusted=true,` +
+		`valid="co2 temperature humidity" 1792152000000000000` + "\n"
+	if buf.String() != want {
+		t.Errorf("Expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestWriteHeader(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, FormatCSV)
+	e.WriteHeader()
+	e.Encode(testRecord())
+	e.WriteHeader()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != lines[2] || !strings.HasPrefix(lines[0], "device,") {
+		t.Errorf("Expected the header before and after the record, got %q", lines)
+	}
+
+	buf.Reset()
+	NewEncoder(&buf, FormatJSON).WriteHeader()
+	if buf.Len() != 0 {
+		t.Errorf("Expected no header for JSON Lines, got %q", buf.String())
+	}
+}
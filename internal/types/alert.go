@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+var SeverityStrings = [...]string{
+	"info",
+	"warning",
+	"critical",
+}
+
+func (s Severity) String() string {
+	if s > SeverityCritical {
+		return "unknown"
+	}
+	return SeverityStrings[s]
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for i, n := range SeverityStrings {
+		if n == name {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", name)
+}
+
+// ActiveAlert is an alert that currently fires.
+type ActiveAlert struct {
+	Name     string
+	Severity Severity
+	Value    float32 // metric value when last evaluated
+	Since    time.Time
+	Snoozed  bool // acknowledged, outputs stay quiet
+}
+
+// Alert returns the most severe alert that is not snoozed, or nil.
+func (r *Readings) Alert() *ActiveAlert {
+	var top *ActiveAlert
+	for i := range r.Alerts {
+		a := &r.Alerts[i]
+		if !a.Snoozed && (top == nil || a.Severity > top.Severity) {
+			top = a
+		}
+	}
+	return top
+}
@@ -0,0 +1,29 @@
+package types
+
+import "time"
+
+// Clock provides the current time to Readings. hal.Clock satisfies it, so
+// tests can drive the history with a fake clock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// SetClock replaces the clock used to timestamp readings.
+func (r *Readings) SetClock(c Clock) {
+	r.clock = c
+}
+
+func (r *Readings) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+func (r *Readings) since(t time.Time) time.Duration {
+	if r.clock == nil {
+		return time.Since(t)
+	}
+	return r.clock.Since(t)
+}
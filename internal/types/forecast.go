@@ -0,0 +1,101 @@
+package types
+
+import "time"
+
+// Forecast configures the CO2 slope and the ventilation estimate.
+type Forecast struct {
+	Window     time.Duration // history span used for the slope fit
+	Thresholds []uint16      // CO2 levels in ppm, ascending; the first one means "ventilate"
+}
+
+// DefaultForecast fits the last 15 minutes and warns before 1000 and
+// 1500 ppm.
+func DefaultForecast() Forecast {
+	return Forecast{
+		Window:     15 * time.Minute,
+		Thresholds: []uint16{1000, 1500},
+	}
+}
+
+const (
+	// minSlopePoints is the number of history entries needed for a slope.
+	minSlopePoints = 3
+
+	// maxForecast is the longest estimate that is still meaningful.
+	maxForecast = 12 * time.Hour
+)
+
+// SetForecast replaces the forecast settings. A non-positive window or
+// empty thresholds disable the estimate.
+func (r *Readings) SetForecast(f Forecast) {
+	r.forecast = f
+}
+
+// calculateForecast fits a least-squares line through the CO2 history of
+// the forecast window and extrapolates it to the next threshold. The fit
+// uses the times the entries were added, as entries are skipped while the
+// CO2 sensor fails.
+func (r *Readings) calculateForecast() {
+	c := &r.Calculated
+	c.CO2Slope, c.CO2SlopeValid = 0, false
+	c.NextThreshold, c.ThresholdIn = 0, 0
+	c.Ventilate = len(r.forecast.Thresholds) > 0 && r.Raw.Valid.Has(CO2) &&
+		r.Raw.CO2 >= r.forecast.Thresholds[0]
+
+	if r.History.Granularity <= 0 || r.forecast.Window <= 0 {
+		return
+	}
+	values := r.History.CO2.Contiguous()
+	times := r.co2Times
+	n := min(len(values), len(times))
+	if n < minSlopePoints {
+		return
+	}
+	values, times = values[len(values)-n:], times[len(times)-n:]
+
+	slope, last := linearFit(times, values)
+	c.CO2Slope = float32(slope)
+	c.CO2SlopeValid = true
+
+	for _, t := range r.forecast.Thresholds {
+		if float64(t) > last {
+			c.NextThreshold = t
+			break
+		}
+	}
+	if c.NextThreshold == 0 || c.CO2Slope <= 0 {
+		return
+	}
+	hours := (float64(c.NextThreshold) - last) / float64(c.CO2Slope)
+	if in := time.Duration(hours * float64(time.Hour)); in <= maxForecast {
+		c.ThresholdIn = max(in.Round(time.Minute), time.Minute)
+	}
+}
+
+// addCO2Time records when a CO2 history entry was added and forgets the
+// entries that left the forecast window.
+func (r *Readings) addCO2Time(t time.Time) {
+	r.co2Times = append(r.co2Times, t)
+	first := 0
+	for first < len(r.co2Times) && t.Sub(r.co2Times[first]) >= r.forecast.Window {
+		first++
+	}
+	r.co2Times = r.co2Times[:copy(r.co2Times, r.co2Times[first:])]
+}
+
+// linearFit returns the slope per hour of the least-squares line through
+// values added at times and the value of that line at the last entry.
+func linearFit(times []time.Time, values []int16) (slope, last float64) {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x, y := times[i].Sub(times[0]).Hours(), float64(v)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept + slope*times[len(times)-1].Sub(times[0]).Hours()
+}
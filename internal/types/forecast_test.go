@@ -0,0 +1,74 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"pico_co2/internal/hal"
+)
+
+func TestForecast(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	r := InitReadings(60)
+	r.SetClock(clock)
+
+	// 5 ppm per minute from 700 ppm, with some noise.
+	noise := []int{0, 3, -2, 1, -3, 2, 0, -1, 2, -2}
+	for i, n := range noise {
+		r.AddReadings(uint16(700+5*i+n), 22, 45)
+		clock.Advance(time.Minute)
+	}
+
+	c := r.Calculated
+	if !c.CO2SlopeValid || c.CO2Slope < 270 || c.CO2Slope > 330 {
+		t.Errorf("Expected a slope of about 300 ppm/h, got %v (%v)", c.CO2Slope, c.CO2SlopeValid)
+	}
+	if c.NextThreshold != 1000 {
+		t.Errorf("Expected next threshold 1000, got %d", c.NextThreshold)
+	}
+	// 745 ppm rising 5 ppm per minute reaches 1000 ppm in about 51 minutes.
+	if c.ThresholdIn < 45*time.Minute || c.ThresholdIn > 57*time.Minute {
+		t.Errorf("Expected about 51m until 1000 ppm, got %s", c.ThresholdIn)
+	}
+	if c.Ventilate {
+		t.Error("Expected no ventilation below the first threshold")
+	}
+
+	// Falling CO2 has no estimate.
+	for i := range 15 {
+		r.AddReadings(uint16(1200-10*i), 22, 45)
+		clock.Advance(time.Minute)
+	}
+	c = r.Calculated
+	if c.CO2Slope >= 0 || c.ThresholdIn != 0 {
+		t.Errorf("Expected a falling slope without estimate, got %v and %s", c.CO2Slope, c.ThresholdIn)
+	}
+	if !c.Ventilate {
+		t.Error("Expected ventilation above the first threshold")
+	}
+}
+
+func TestForecastAcrossSensorOutage(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	r := InitReadings(60)
+	r.SetClock(clock)
+
+	// 5 ppm per minute, with no CO2 from minute 5 to 9.
+	for i := range 15 {
+		if i >= 5 && i < 10 {
+			r.Add(RawReadings{Temperature: 22, Humidity: 45, Valid: Temperature | Humidity})
+		} else {
+			r.AddReadings(uint16(700+5*i), 22, 45)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	c := r.Calculated
+	if !c.CO2SlopeValid || c.CO2Slope < 299 || c.CO2Slope > 301 {
+		t.Errorf("Expected a slope of 300 ppm/h across the gap, got %v (%v)", c.CO2Slope, c.CO2SlopeValid)
+	}
+	// 770 ppm rising 5 ppm per minute reaches 1000 ppm in 46 minutes.
+	if c.ThresholdIn != 46*time.Minute {
+		t.Errorf("Expected 46m until 1000 ppm, got %s", c.ThresholdIn)
+	}
+}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// MaxStaleReads is the number of consecutive failed reads during which the
+// last good values of a sensor are still shown.
+const MaxStaleReads = 3
+
+type SensorState uint8
+
+const (
+	SensorOK     SensorState = iota // last read succeeded
+	SensorStale                     // last read failed, previous values kept
+	SensorFailed                    // no usable values
+)
+
+var SensorStateStrings = [...]string{
+	"ok",
+	"stale",
+	"failed",
+}
+
+func (s SensorState) String() string {
+	if s > SensorFailed {
+		return "unknown"
+	}
+	return SensorStateStrings[s]
+}
+
+func (s SensorState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SensorHealth tracks the state of a single sensor across read cycles.
+type SensorHealth struct {
+	Name          string
+	State         SensorState
+	LastError     string
+	Failures      int      // consecutive failed reads
+	TotalFailures int      // failed reads since start
+	Reinits       int      // re-initialisations after repeated failures
+	Provides      Quantity // quantities delivered by the last good read
+	LastOK        time.Time
+}
+
+// record updates the health after a read attempt that delivered provided.
+func (h *SensorHealth) record(provided Quantity, err error, now time.Time) {
+	if err == nil {
+		h.State = SensorOK
+		h.LastError = ""
+		h.Failures = 0
+		h.Provides = provided
+		h.LastOK = now
+		return
+	}
+
+	h.LastError = err.Error()
+	h.Failures++
+	h.TotalFailures++
+
+	if h.LastOK.IsZero() || h.Failures >= MaxStaleReads {
+		h.State = SensorFailed
+	} else {
+		h.State = SensorStale
+	}
+}
+
+// RecordSensor updates the health of the named sensor after a read attempt.
+// provided holds the quantities the sensor filled in on success.
+func (r *Readings) RecordSensor(name string, provided Quantity, err error) {
+	h := r.sensorHealth(name)
+	h.record(provided, err, r.now())
+}
+
+// Sensor returns the health of the named sensor, or nil if it never reported.
+func (r *Readings) Sensor(name string) *SensorHealth {
+	for i := range r.Sensors {
+		if r.Sensors[i].Name == name {
+			return &r.Sensors[i]
+		}
+	}
+	return nil
+}
+
+// AllSensorsFailed reports whether no sensor currently delivers values.
+func (r *Readings) AllSensorsFailed() bool {
+	if len(r.Sensors) == 0 {
+		return false
+	}
+	for _, h := range r.Sensors {
+		if h.State != SensorFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// SensorErrors joins the last errors of all failing sensors.
+func (r *Readings) SensorErrors() string {
+	var errs []string
+	for _, h := range r.Sensors {
+		if h.State != SensorOK && h.LastError != "" {
+			errs = append(errs, h.LastError)
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+func (r *Readings) sensorHealth(name string) *SensorHealth {
+	if h := r.Sensor(name); h != nil {
+		return h
+	}
+	r.Sensors = append(r.Sensors, SensorHealth{Name: name})
+	return &r.Sensors[len(r.Sensors)-1]
+}
+
+// staleQuantities returns the quantities whose sensor is stale, so their last
+// values are still shown.
+func (r *Readings) staleQuantities() Quantity {
+	var q Quantity
+	for _, h := range r.Sensors {
+		if h.State == SensorStale {
+			q |= h.Provides
+		}
+	}
+	return q
+}
+
+// copyQuantities copies the values of the quantities in q from src.
+func (raw *RawReadings) copyQuantities(src RawReadings, q Quantity) {
+	if q.Has(CO2) {
+		raw.CO2 = src.CO2
+	}
+	if q.Has(Temperature) {
+		raw.Temperature = src.Temperature
+	}
+	if q.Has(Humidity) {
+		raw.Humidity = src.Humidity
+	}
+	if q.Has(TVOC) {
+		raw.TVOC = src.TVOC
+	}
+	if q.Has(ECO2) {
+		raw.ECO2 = src.ECO2
+	}
+	if q.Has(AQI) {
+		raw.AQI = src.AQI
+	}
+	if q.Has(Pressure) {
+		raw.Pressure = src.Pressure
+	}
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSensorHealthStaleThenFailed(t *testing.T) {
+	r := InitReadings(16)
+	errRead := errors.New("scd4x read: i2c timeout")
+
+	r.RecordSensor("aht20", Temperature|Humidity, nil)
+	r.RecordSensor("scd4x", CO2, nil)
+	r.Add(RawReadings{CO2: 900, Temperature: 21, Humidity: 40, Valid: CO2 | Temperature | Humidity})
+
+	// First failure: the last CO2 value is kept and marked valid.
+	r.RecordSensor("aht20", Temperature|Humidity, nil)
+	r.RecordSensor("scd4x", 0, errRead)
+	r.Add(RawReadings{Temperature: 22, Humidity: 41, Valid: Temperature | Humidity})
+
+	h := r.Sensor("scd4x")
+	if h.State != SensorStale || h.Failures != 1 {
+		t.Fatalf("Expected stale with 1 failure, got %v with %d", h.State, h.Failures)
+	}
+	if !r.Raw.Valid.Has(CO2) || r.Raw.CO2 != 900 {
+		t.Errorf("Expected stale CO2 900 to be kept, got %d (valid %t)", r.Raw.CO2, r.Raw.Valid.Has(CO2))
+	}
+	if r.Raw.Temperature != 22 {
+		t.Errorf("Expected fresh temperature 22, got %.1f", r.Raw.Temperature)
+	}
+	if r.History.CO2.Len() != 1 {
+		t.Errorf("Expected stale value not to be added to history, got %d entries", r.History.CO2.Len())
+	}
+
+	for range MaxStaleReads - 1 {
+		r.RecordSensor("scd4x", 0, errRead)
+		r.Add(RawReadings{Temperature: 22, Humidity: 41, Valid: Temperature | Humidity})
+	}
+
+	if h := r.Sensor("scd4x"); h.State != SensorFailed || h.LastError != errRead.Error() {
+		t.Fatalf("Expected failed with last error, got %v %q", h.State, h.LastError)
+	}
+	if r.Raw.Valid.Has(CO2) {
+		t.Error("Expected CO2 to be invalid once the sensor failed")
+	}
+	if r.AllSensorsFailed() {
+		t.Error("Expected aht20 to keep the readings alive")
+	}
+
+	r.RecordSensor("scd4x", CO2, nil)
+	if h := r.Sensor("scd4x"); h.State != SensorOK || h.Failures != 0 || h.TotalFailures != MaxStaleReads {
+		t.Errorf("Expected recovery, got %v failures=%d total=%d", h.State, h.Failures, h.TotalFailures)
+	}
+}
+
+func TestSensorFailingOnFirstReadIsFailed(t *testing.T) {
+	r := InitReadings(16)
+	r.RecordSensor("scd4x", 0, errors.New("no ack"))
+
+	if h := r.Sensor("scd4x"); h.State != SensorFailed {
+		t.Errorf("Expected failed, got %v", h.State)
+	}
+	if !r.AllSensorsFailed() {
+		t.Error("Expected all sensors failed")
+	}
+	if r.SensorErrors() != "no ack" {
+		t.Errorf("Unexpected errors %q", r.SensorErrors())
+	}
+}
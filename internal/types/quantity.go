@@ -0,0 +1,61 @@
+package types
+
+// Quantity identifies a measured physical quantity. Values are bit flags, so
+// a set of quantities fits into a single Quantity.
+type Quantity uint16
+
+const (
+	CO2         Quantity = 1 << iota // ppm, NDIR
+	Temperature                      // °C
+	Humidity                         // %RH
+	TVOC                             // ppb
+	ECO2                             // ppm, equivalent CO2
+	AQI                              // UBA index minus one, 0–4
+	Pressure                         // hPa
+)
+
+// AllQuantities lists every known quantity in display order.
+var AllQuantities = [...]Quantity{
+	CO2,
+	Temperature,
+	Humidity,
+	TVOC,
+	ECO2,
+	AQI,
+	Pressure,
+}
+
+var quantityNames = [...]string{
+	"co2",
+	"temperature",
+	"humidity",
+	"tvoc",
+	"eco2",
+	"aqi",
+	"pressure",
+}
+
+// Has reports whether all quantities in o are set in q.
+func (q Quantity) Has(o Quantity) bool {
+	return q&o == o
+}
+
+// String returns the lower-case name of a single quantity.
+func (q Quantity) String() string {
+	for i, v := range AllQuantities {
+		if q == v {
+			return quantityNames[i]
+		}
+	}
+	return "unknown"
+}
+
+// ParseQuantity returns the quantity with the given String name.
+func ParseQuantity(name string) (Quantity, bool) {
+	for i, n := range quantityNames {
+		if n == name {
+			return AllQuantities[i], true
+		}
+	}
+	return 0, false
+}
@@ -17,12 +17,21 @@ type Readings struct {
 	IsDrawen       bool
 	Error          string
 	Time           Time
+	Sensors        []SensorHealth
+	Alerts         []ActiveAlert   // most severe first
+	TempUnit       TemperatureUnit // unit of the temperatures shown on the screens
+	forecast       Forecast
+	co2Times       []time.Time // when the CO2 history entries of the forecast window were added
+	clock          Clock
 }
 
+// Time is the local wall clock time shown on the screens.
 type Time struct {
-	Hour    int
-	Minute  int
+	Hour     int
+	Minute   int
 	LastRead time.Time
+	RTC      time.Time // RTC time at LastRead
+	Trusted  bool      // RTC time is known to be correct
 }
 
 type RawReadings struct {
@@ -31,6 +40,9 @@ type RawReadings struct {
 	CO2         uint16
 	TVOC        uint16
 	AQI         uint8
+	ECO2        uint16
+	Pressure    float32
+	Valid       Quantity // quantities filled in by the sensors
 }
 
 type MeasurementHistory struct {
@@ -38,15 +50,42 @@ type MeasurementHistory struct {
 	Temperature   *fifo.FIFO16
 	Humidity      *fifo.FIFO16
 	HeatIndexTemp *fifo.FIFO16
+	TVOC          *fifo.FIFO16
+	ECO2          *fifo.FIFO16
+	AQI           *fifo.FIFO16
 	AddedAt       time.Time
 	Granularity   time.Duration
 }
 
+// Of returns the history of q, or nil if q is not recorded.
+func (h *MeasurementHistory) Of(q Quantity) *fifo.FIFO16 {
+	switch q {
+	case CO2:
+		return h.CO2
+	case Temperature:
+		return h.Temperature
+	case Humidity:
+		return h.Humidity
+	case TVOC:
+		return h.TVOC
+	case ECO2:
+		return h.ECO2
+	case AQI:
+		return h.AQI
+	}
+	return nil
+}
+
 type CalculatedReadings struct {
 	CO215MinAverage uint16
 	CO25MinAvgPrev  uint16
 	CO25MinAvgCurr  uint16
 	CO2Trend        status.CO2Trend
+	CO2Slope        float32 // ppm per hour over the forecast window
+	CO2SlopeValid   bool
+	Ventilate       bool          // CO2 is at or above the first forecast threshold
+	NextThreshold   uint16        // lowest forecast threshold above the fitted CO2, 0 if none
+	ThresholdIn     time.Duration // estimated time until NextThreshold, 0 if not rising
 }
 
 func InitReadings(queueSize int) *Readings {
@@ -56,40 +95,85 @@ func InitReadings(queueSize int) *Readings {
 			Temperature:   fifo.NewFIFO16(queueSize),
 			Humidity:      fifo.NewFIFO16(queueSize),
 			HeatIndexTemp: fifo.NewFIFO16(queueSize),
+			TVOC:          fifo.NewFIFO16(queueSize),
+			ECO2:          fifo.NewFIFO16(queueSize),
+			AQI:           fifo.NewFIFO16(queueSize),
 			Granularity:   time.Minute,
 		},
 		Calculated: CalculatedReadings{
 			CO2Trend: status.UnknownCO2Trend,
 		},
+		forecast: DefaultForecast(),
 	}
 }
 
+// AddReadings stores a CO2, temperature and humidity measurement.
 func (r *Readings) AddReadings(
 	co2 uint16,
 	temperature float32,
 	humidity float32,
 ) {
+	r.Add(RawReadings{
+		CO2:         co2,
+		Temperature: temperature,
+		Humidity:    humidity,
+		Valid:       CO2 | Temperature | Humidity,
+	})
+}
+
+// Add stores a set of raw measurements and updates history and calculated
+// values. Only quantities marked in raw.Valid are added to the history.
+// Values of stale sensors are carried over from the previous reading.
+func (r *Readings) Add(raw RawReadings) {
+	stale := r.staleQuantities() & r.Raw.Valid &^ raw.Valid
+	raw.copyQuantities(r.Raw, stale)
+
+	var (
+		co2         = raw.CO2
+		temperature = raw.Temperature
+		humidity    = raw.Humidity
+	)
+
 	r.Error = ""
-	r.LastUpdateAt = time.Now()
+	r.LastUpdateAt = r.now()
 
 	if r.FirstReadingAt.IsZero() {
-		r.FirstReadingAt = time.Now()
+		r.FirstReadingAt = r.now()
 	}
 
 	if r.History.CO2 == nil || r.History.Temperature == nil ||
-		r.History.Humidity == nil || r.History.HeatIndexTemp == nil {
+		r.History.Humidity == nil || r.History.HeatIndexTemp == nil ||
+		r.History.TVOC == nil || r.History.ECO2 == nil || r.History.AQI == nil {
 		return
 	}
 
-	if time.Since(r.History.AddedAt) > r.History.Granularity {
-		if co2 > 0 {
+	// Readings exactly one granularity apart, as a fake clock or a
+	// scheduler without drift delivers them, each get an entry.
+	if r.since(r.History.AddedAt) >= r.History.Granularity {
+		if raw.Valid.Has(CO2) && co2 > 0 {
 			r.History.CO2.Enqueue(int16(co2))
+			r.addCO2Time(r.now())
+		}
+		if raw.Valid.Has(Temperature) {
+			r.History.Temperature.Enqueue(int16(math.Round(float64(temperature))))
+		}
+		if raw.Valid.Has(Humidity) {
+			r.History.Humidity.Enqueue(int16(math.Round(float64(humidity))))
 		}
-		r.History.Temperature.Enqueue(int16(math.Round(float64(temperature))))
-		r.History.Humidity.Enqueue(int16(math.Round(float64(humidity))))
-		hiVal := status.HeatIndexVal(temperature, humidity)
-		r.History.HeatIndexTemp.Enqueue(int16(math.Round(float64(hiVal))))
-		r.History.AddedAt = time.Now()
+		if raw.Valid.Has(Temperature | Humidity) {
+			hiVal := status.HeatIndexVal(temperature, humidity)
+			r.History.HeatIndexTemp.Enqueue(int16(math.Round(float64(hiVal))))
+		}
+		if raw.Valid.Has(TVOC) {
+			r.History.TVOC.Enqueue(clampInt16(raw.TVOC))
+		}
+		if raw.Valid.Has(ECO2) {
+			r.History.ECO2.Enqueue(clampInt16(raw.ECO2))
+		}
+		if raw.Valid.Has(AQI) {
+			r.History.AQI.Enqueue(int16(raw.AQI))
+		}
+		r.History.AddedAt = r.now()
 	}
 
 	// Calculate 15-minute average of last 15 readings
@@ -121,11 +205,10 @@ func (r *Readings) AddReadings(
 	// Store last measurements before updating with new ones
 	r.LastRaw = r.Raw
 
-	r.Raw = RawReadings{
-		CO2:         co2,
-		Temperature: temperature,
-		Humidity:    humidity,
-	}
+	raw.Valid |= stale
+	r.Raw = raw
+
+	r.calculateForecast()
 }
 
 func (r *Readings) calculateCO2Trend() {
@@ -188,3 +271,12 @@ func (r *Readings) calculateCO2Trend() {
 	r.Calculated.CO25MinAvgPrev = prevAvg
 	r.Calculated.CO25MinAvgCurr = currAvg
 }
+
+// clampInt16 converts v for storage in an int16 history, saturating at the
+// maximum value.
+func clampInt16(v uint16) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	return int16(v)
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"pico_co2/internal/hal"
 	"pico_co2/internal/types/status"
 )
 
@@ -93,3 +94,75 @@ func TestCO2TrendString(t *testing.T) {
 		})
 	}
 }
+
+func TestAddOnlyValidQuantities(t *testing.T) {
+	r := InitReadings(16)
+
+	r.Add(RawReadings{
+		Temperature: 23.4,
+		Humidity:    41.6,
+		Valid:       Temperature | Humidity,
+	})
+
+	if r.History.CO2.Len() != 0 {
+		t.Errorf("Expected no CO2 history, got %d entries", r.History.CO2.Len())
+	}
+	if r.History.Temperature.Len() != 1 || r.History.Humidity.Len() != 1 {
+		t.Errorf("Expected one temperature and humidity entry, got %d and %d",
+			r.History.Temperature.Len(), r.History.Humidity.Len())
+	}
+	if !r.Raw.Valid.Has(Temperature) || r.Raw.Valid.Has(CO2) {
+		t.Errorf("Unexpected validity mask %b", r.Raw.Valid)
+	}
+}
+
+func TestSimulateDayWithFakeClock(t *testing.T) {
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	clock := hal.NewFakeClock(start)
+	r := InitReadings(480)
+	r.SetClock(clock)
+
+	for i := range 24 * 60 {
+		r.AddReadings(uint16(400+i%600), 22.0, 50.0)
+		clock.Advance(time.Minute)
+	}
+
+	if !r.FirstReadingAt.Equal(start) {
+		t.Errorf("Expected first reading at %v, got %v", start, r.FirstReadingAt)
+	}
+	if want := start.Add(24*time.Hour - time.Minute); !r.LastUpdateAt.Equal(want) {
+		t.Errorf("Expected last update at %v, got %v", want, r.LastUpdateAt)
+	}
+	if r.History.CO2.Len() != 480 {
+		t.Errorf("Expected full CO2 history of 480, got %d", r.History.CO2.Len())
+	}
+
+	// Readings faster than the history granularity are not stored.
+	r.History.CO2.Reset()
+	for range 10 {
+		r.AddReadings(800, 22.0, 50.0)
+		clock.Advance(10 * time.Second)
+	}
+	if r.History.CO2.Len() != 2 {
+		t.Errorf("Expected 2 CO2 entries in 100 seconds, got %d", r.History.CO2.Len())
+	}
+}
+
+func TestHistoryGranularityBoundary(t *testing.T) {
+	clock := hal.NewFakeClock(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	r := InitReadings(16)
+	r.SetClock(clock)
+
+	r.AddReadings(800, 22.0, 50.0)
+	clock.Advance(r.History.Granularity - time.Millisecond)
+	r.AddReadings(800, 22.0, 50.0)
+	if r.History.CO2.Len() != 1 {
+		t.Fatalf("Expected no entry before one granularity, got %d entries", r.History.CO2.Len())
+	}
+
+	clock.Advance(time.Millisecond)
+	r.AddReadings(800, 22.0, 50.0)
+	if r.History.CO2.Len() != 2 {
+		t.Errorf("Expected an entry exactly one granularity later, got %d entries", r.History.CO2.Len())
+	}
+}
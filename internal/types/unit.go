@@ -0,0 +1,53 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TemperatureUnit is the unit of the temperatures shown on the screens.
+// Readings, history and telemetry stay in degrees Celsius.
+type TemperatureUnit uint8
+
+const (
+	Celsius TemperatureUnit = iota
+	Fahrenheit
+)
+
+var TemperatureUnitStrings = [...]string{
+	"C",
+	"F",
+}
+
+func (u TemperatureUnit) String() string {
+	if u > Fahrenheit {
+		return "unknown"
+	}
+	return TemperatureUnitStrings[u]
+}
+
+func (u TemperatureUnit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+func (u *TemperatureUnit) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for i, n := range TemperatureUnitStrings {
+		if n == name {
+			*u = TemperatureUnit(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown temperature unit %q", name)
+}
+
+// FromCelsius converts c in degrees Celsius to u.
+func (u TemperatureUnit) FromCelsius(c float32) float32 {
+	if u == Fahrenheit {
+		return c*9/5 + 32
+	}
+	return c
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"tinygo.org/x/drivers"
@@ -18,20 +19,42 @@ const (
 	longTimeout    = 1 * time.Second
 )
 
+// PartIDError is returned by Configure when the device at the address is
+// not an ENS160.
+type PartIDError struct {
+	Addr   uint16
+	PartID uint16
+}
+
+func (e *PartIDError) Error() string {
+	return fmt.Sprintf("ENS160: unexpected part ID %#04x at address %#02x", e.PartID, e.Addr)
+}
+
+// FirmwareVersion is the version of the application firmware.
+type FirmwareVersion struct {
+	Major, Minor, Release uint8
+}
+
+func (v FirmwareVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Release)
+}
+
 // Device wraps an I2C connection to an ENS160 device.
 type Device struct {
 	bus  drivers.I2C // I²C implementation
 	addr uint16      // 7‑bit bus address, promoted to uint16 per drivers.I2C
+	mode uint8       // last operating mode set
 
 	// shadow registers / last measurements
 	lastTvocPPB  uint16
 	lastEco2PPM  uint16
 	lastAqiUBA   uint8
 	lastValidity uint8 // Store the latest validity status
+	firmware     FirmwareVersion
 
 	// pre‑allocated buffers
 	wbuf [6]byte // longest write: reg + 4 bytes (TEMP+RH)
-	rbuf [5]byte // longest read: DATA burst (5 bytes)
+	rbuf [8]byte // longest read: GPR_READ burst (8 bytes)
 }
 
 // New returns a new ENS160 driver.
@@ -42,16 +65,27 @@ func New(bus drivers.I2C, addr uint16) *Device {
 	return &Device{bus: bus, addr: addr}
 }
 
-// Configure sets up the device for reading.
+// Configure sets up the device for reading. It returns a *PartIDError if
+// the device is not an ENS160.
 func (d *Device) Configure() error {
 	// 1. Soft‑reset
-	if err := d.write1(regOpMode, ModeReset); err != nil {
+	if err := d.setMode(ModeReset); err != nil {
 		return err
 	}
 	time.Sleep(defaultTimeout)
 
-	// 2. Enter IDLE, clear GPR registers, then go STANDARD.
-	if err := d.write1(regOpMode, ModeIdle); err != nil {
+	// 2. Check that this is an ENS160.
+	id, err := d.ReadPartID()
+	if err != nil {
+		return fmt.Errorf("ENS160: part ID read failed: %w", err)
+	}
+	if id != PartID {
+		return &PartIDError{Addr: d.addr, PartID: id}
+	}
+
+	// 3. Enter IDLE, clear GPR registers, read the firmware version, then
+	// go STANDARD.
+	if err := d.setMode(ModeIdle); err != nil {
 		return err
 	}
 	time.Sleep(defaultTimeout)
@@ -61,7 +95,11 @@ func (d *Device) Configure() error {
 	}
 	time.Sleep(defaultTimeout)
 
-	if err := d.write1(regOpMode, ModeStandard); err != nil {
+	if d.firmware, err = d.GetFirmwareVersion(); err != nil {
+		return err
+	}
+
+	if err := d.setMode(ModeStandard); err != nil {
 		return err
 	}
 	time.Sleep(longTimeout)
@@ -69,6 +107,50 @@ func (d *Device) Configure() error {
 	return nil
 }
 
+// ReadPartID reads the PART_ID register, which holds PartID on an ENS160.
+func (d *Device) ReadPartID() (uint16, error) {
+	d.wbuf[0] = regPartID
+	if err := d.bus.Tx(d.addr, d.wbuf[:1], d.rbuf[:2]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(d.rbuf[:2]), nil
+}
+
+// GetFirmwareVersion reads the firmware version with the GET_APPVER
+// command. Commands are only accepted in IDLE mode, so the device is put
+// into IDLE and back into its previous mode, which takes a second from
+// STANDARD.
+func (d *Device) GetFirmwareVersion() (FirmwareVersion, error) {
+	prev := d.mode
+	if prev != ModeIdle {
+		if err := d.Wake(); err != nil {
+			return FirmwareVersion{}, err
+		}
+	}
+
+	if err := d.write1(regCommand, cmdGetAppVer); err != nil {
+		return FirmwareVersion{}, err
+	}
+	time.Sleep(defaultTimeout)
+
+	d.wbuf[0] = regGPRRead + 4 // GPR_READ4..6: major, minor, release
+	if err := d.bus.Tx(d.addr, d.wbuf[:1], d.rbuf[:3]); err != nil {
+		return FirmwareVersion{}, fmt.Errorf("ENS160: firmware version read failed: %w", err)
+	}
+	v := FirmwareVersion{Major: d.rbuf[0], Minor: d.rbuf[1], Release: d.rbuf[2]}
+
+	switch prev {
+	case ModeStandard:
+		return v, d.EnableMeasurements()
+	case ModeDeepSleep:
+		return v, d.Sleep()
+	}
+	return v, nil
+}
+
+// Firmware returns the firmware version read by Configure.
+func (d *Device) Firmware() FirmwareVersion { return d.firmware }
+
 // SetEnvDataMilli sets the ambient temperature and humidity for compensation.
 //
 // tempMilliC is the temperature in milli-degrees Celsius.
@@ -152,6 +234,37 @@ func (d *Device) Update(which drivers.Measurement) error {
 	return nil
 }
 
+// ReadCompensation reads back the temperature and humidity the sensor
+// applies for compensation, in milli-degrees Celsius and milli-percent,
+// e.g. to confirm SetEnvDataMilli.
+func (d *Device) ReadCompensation() (tempMilliC, rhMilliPct int32, err error) {
+	d.wbuf[0] = regDataT // DATA_T and DATA_RH (auto‑increment)
+	if err := d.bus.Tx(d.addr, d.wbuf[:1], d.rbuf[:4]); err != nil {
+		return 0, 0, err
+	}
+	tempRaw := int32(binary.LittleEndian.Uint16(d.rbuf[0:2])) // Kelvin×64
+	humRaw := int32(binary.LittleEndian.Uint16(d.rbuf[2:4]))  // %RH×512
+	return tempRaw*1000/64 - 273150, humRaw * 1000 / 512, nil
+}
+
+// ReadResistances reads the raw resistances of hot plates 0 and 3 in ohms.
+// In STANDARD mode the sensor updates them with every measurement.
+func (d *Device) ReadResistances() (hp0, hp3 float32, err error) {
+	d.wbuf[0] = regGPRRead // GPR_READ0/1 hold HP0, GPR_READ6/7 hold HP3
+	if err := d.bus.Tx(d.addr, d.wbuf[:1], d.rbuf[:8]); err != nil {
+		return 0, 0, err
+	}
+	hp0 = Resistance(binary.LittleEndian.Uint16(d.rbuf[0:2]))
+	hp3 = Resistance(binary.LittleEndian.Uint16(d.rbuf[6:8]))
+	return hp0, hp3, nil
+}
+
+// Resistance converts a raw resistance value, log2 of the resistance
+// times 2048, to ohms.
+func Resistance(raw uint16) float32 {
+	return float32(math.Exp2(float64(raw) / 2048))
+}
+
 // TVOC returns the last total‑VOC concentration in parts‑per‑billion.
 func (d *Device) TVOC() uint16 { return d.lastTvocPPB }
 
@@ -169,13 +282,13 @@ func (d *Device) Validity() uint8 {
 // Sleep puts the device into deep sleep mode to minimize power consumption
 // and self-heating.
 func (d *Device) Sleep() error {
-	return d.write1(regOpMode, ModeDeepSleep)
+	return d.setMode(ModeDeepSleep)
 }
 
 // Wake sets the device to idle mode. From here you can set it to standard mode
 // when ready to take measurements.
 func (d *Device) Wake() error {
-	if err := d.write1(regOpMode, ModeIdle); err != nil {
+	if err := d.setMode(ModeIdle); err != nil {
 		return err
 	}
 	time.Sleep(defaultTimeout)
@@ -184,12 +297,22 @@ func (d *Device) Wake() error {
 
 // EnableMeasurements sets the device to standard measurement mode.
 func (d *Device) EnableMeasurements() error {
-	if err := d.write1(regOpMode, ModeStandard); err != nil {
+	if err := d.setMode(ModeStandard); err != nil {
 		return err
 	}
 	time.Sleep(longTimeout)
 	return nil
 }
+
+// setMode writes the OPMODE register.
+func (d *Device) setMode(mode uint8) error {
+	if err := d.write1(regOpMode, mode); err != nil {
+		return err
+	}
+	d.mode = mode
+	return nil
+}
+
 // write1 writes a single byte to a register.
 func (d *Device) write1(reg, val uint8) error {
 	d.wbuf[0] = reg
@@ -0,0 +1,134 @@
+package ens160
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakeBus emulates the ENS160 register file. It records the operating
+// modes written, answers GET_APPVER with firmware 5.4.6 and applies the
+// compensation data written right away.
+type fakeBus struct {
+	regs  [256]byte
+	modes []uint8
+}
+
+func newFakeBus() *fakeBus {
+	b := &fakeBus{}
+	b.regs[regPartID], b.regs[regPartID+1] = 0x60, 0x01
+	return b
+}
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	if addr != DefaultAddress {
+		return errors.New("nack")
+	}
+	if len(w) == 0 {
+		return nil
+	}
+	reg := int(w[0])
+	copy(b.regs[reg:], w[1:])
+	switch {
+	case reg == regTempIn && len(w) == 5:
+		copy(b.regs[regDataT:], w[1:]) // applied with the next measurement
+	case reg == regOpMode && len(w) == 2:
+		b.modes = append(b.modes, w[1])
+	case reg == regCommand && len(w) == 2 && w[1] == cmdGetAppVer:
+		copy(b.regs[regGPRRead+4:], []byte{5, 4, 6})
+	}
+	copy(r, b.regs[reg:])
+	return nil
+}
+
+func TestConfigureChecksPartID(t *testing.T) {
+	bus := newFakeBus()
+	bus.regs[regPartID] = 0x61
+	d := New(bus, 0)
+
+	var idErr *PartIDError
+	if err := d.Configure(); !errors.As(err, &idErr) || idErr.PartID != 0x0161 || idErr.Addr != DefaultAddress {
+		t.Fatalf("Expected a part ID error for 0x0161, got %v", err)
+	}
+
+	bus.regs[regPartID] = 0x60
+	if err := d.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Firmware().String(); got != "5.4.6" {
+		t.Errorf("Expected firmware 5.4.6, got %s", got)
+	}
+}
+
+func TestFirmwareVersionRestoresMode(t *testing.T) {
+	tests := []struct {
+		mode  uint8
+		modes []uint8 // written by GetFirmwareVersion
+	}{
+		{ModeIdle, nil},
+		{ModeStandard, []uint8{ModeIdle, ModeStandard}},
+		{ModeDeepSleep, []uint8{ModeIdle, ModeDeepSleep}},
+	}
+	for _, tt := range tests {
+		bus := newFakeBus()
+		d := New(bus, 0)
+		d.mode = tt.mode
+
+		v, err := d.GetFirmwareVersion()
+		if err != nil || v != (FirmwareVersion{5, 4, 6}) {
+			t.Errorf("mode %#x: expected firmware 5.4.6, got %s, %v", tt.mode, v, err)
+		}
+		if !slices.Equal(bus.modes, tt.modes) || d.mode != tt.mode {
+			t.Errorf("mode %#x: expected modes %x and back in %#x, got %x and %#x",
+				tt.mode, tt.modes, tt.mode, bus.modes, d.mode)
+		}
+	}
+}
+
+func TestCompensationRoundTrip(t *testing.T) {
+	tests := []struct {
+		tempMilliC, rhMilliPct int32
+		tempRaw, humRaw        uint16 // Kelvin×64, %RH×512
+		wantTemp, wantRH       int32
+	}{
+		{25000, 50000, 19081, 25600, 24990, 50000},
+		{-45000, -1000, 14921, 0, -40010, 0},          // clipped to -40 °C, 0 %
+		{100000, 120000, 22921, 51200, 84990, 100000}, // clipped to 85 °C, 100 %
+	}
+	for _, tt := range tests {
+		bus := newFakeBus()
+		d := New(bus, 0)
+
+		if err := d.SetEnvDataMilli(tt.tempMilliC, tt.rhMilliPct); err != nil {
+			t.Fatal(err)
+		}
+		tempRaw := uint16(bus.regs[regTempIn]) | uint16(bus.regs[regTempIn+1])<<8
+		humRaw := uint16(bus.regs[regHumIn]) | uint16(bus.regs[regHumIn+1])<<8
+		if tempRaw != tt.tempRaw || humRaw != tt.humRaw {
+			t.Errorf("%d m°C, %d m%%: expected raw %d, %d, got %d, %d",
+				tt.tempMilliC, tt.rhMilliPct, tt.tempRaw, tt.humRaw, tempRaw, humRaw)
+		}
+
+		temp, rh, err := d.ReadCompensation()
+		if err != nil || temp != tt.wantTemp || rh != tt.wantRH {
+			t.Errorf("%d m°C, %d m%%: expected %d m°C, %d m%% back, got %d, %d, %v",
+				tt.tempMilliC, tt.rhMilliPct, tt.wantTemp, tt.wantRH, temp, rh, err)
+		}
+	}
+}
+
+func TestReadResistances(t *testing.T) {
+	bus := newFakeBus()
+	copy(bus.regs[regGPRRead:], []byte{0x00, 0x88, 0, 0, 0, 0, 0x00, 0xA0})
+	d := New(bus, 0)
+
+	hp0, hp3, err := d.ReadResistances()
+	if err != nil || hp0 != 1<<17 || hp3 != 1<<20 {
+		t.Errorf("Expected 2^17 and 2^20 ohm, got %v, %v, %v", hp0, hp3, err)
+	}
+	for raw, want := range map[uint16]float32{0: 1, 2048: 2, 3072: 2.828427} {
+		if got := Resistance(raw); got < want-1e-5 || got > want+1e-5 {
+			t.Errorf("Resistance(%d) = %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -1,3 +1,5 @@
+//go:build tinygo
+
 // This example demonstrates ENS160 usage.
 //
 // Wiring:
@@ -29,6 +31,7 @@ func main() {
 	if err := dev.Configure(); err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("ENS160 firmware %s\n", dev.Firmware())
 
 	for {
 		err := dev.Update(drivers.Concentration)
@@ -3,6 +3,9 @@ package ens160
 // DefaultAddress is the default I2C address for the ENS160.
 const DefaultAddress = 0x53
 
+// PartID is the content of the PART_ID register of an ENS160.
+const PartID = 0x0160
+
 // Registers
 const (
 	regPartID   = 0x00
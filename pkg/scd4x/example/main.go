@@ -0,0 +1,64 @@
+//go:build tinygo
+
+// This example demonstrates SCD4x usage.
+//
+// Wiring:
+// - VCC to 3.3V, GND to ground
+// - SDA to board SDA, SCL to board SCL
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"machine"
+	"tinygo.org/x/drivers"
+
+	"pico_co2/pkg/scd4x"
+)
+
+func main() {
+	err := machine.I2C0.Configure(machine.I2CConfig{
+		Frequency: 100 * machine.KHz,
+	})
+	if err != nil {
+		log.Fatal("Failed to configure I2C:", err)
+	}
+
+	// The sensor needs a second after power-up.
+	time.Sleep(time.Second)
+
+	dev := scd4x.New(machine.I2C0, scd4x.DefaultAddress)
+	if err := dev.Configure(); err != nil {
+		log.Fatal(err)
+	}
+	if sn, err := dev.SerialNumber(); err == nil {
+		fmt.Printf("SCD4x serial %012X\n", sn)
+	}
+	if err := dev.StartPeriodicMeasurement(); err != nil {
+		log.Fatal(err)
+	}
+
+	for {
+		time.Sleep(scd4x.PeriodicInterval)
+
+		err := dev.Update(drivers.Concentration)
+		if errors.Is(err, scd4x.ErrNotReady) {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error reading SCD4x: %v\n", err)
+			continue
+		}
+
+		fmt.Printf(
+			"CO₂=%dppm, T=%.2f°C, RH=%.1f%%\n",
+			dev.CO2(),
+			float32(dev.Temperature())/1000,
+			float32(dev.Humidity())/1000,
+		)
+	}
+}
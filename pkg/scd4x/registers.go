@@ -0,0 +1,68 @@
+package scd4x
+
+import "time"
+
+// DefaultAddress is the I2C address of the SCD4x.
+const DefaultAddress = 0x62
+
+// Commands
+const (
+	cmdStartPeriodicMeasurement         = 0x21B1
+	cmdReadMeasurement                  = 0xEC05
+	cmdStopPeriodicMeasurement          = 0x3F86
+	cmdSetTemperatureOffset             = 0x241D
+	cmdGetTemperatureOffset             = 0x2318
+	cmdSetSensorAltitude                = 0x2427
+	cmdGetSensorAltitude                = 0x2322
+	cmdSetAmbientPressure               = 0xE000
+	cmdPerformForcedRecalibration       = 0x362F
+	cmdSetAutomaticSelfCalibration      = 0x2416
+	cmdGetAutomaticSelfCalibration      = 0x2313
+	cmdStartLowPowerPeriodicMeasurement = 0x21AC
+	cmdGetDataReadyStatus               = 0xE4B8
+	cmdPersistSettings                  = 0x3615
+	cmdGetSerialNumber                  = 0x3682
+	cmdPerformSelfTest                  = 0x3639
+	cmdPerformFactoryReset              = 0x3632
+	cmdReinit                           = 0x3646
+	cmdMeasureSingleShot                = 0x219D
+	cmdPowerDown                        = 0x36E0
+	cmdWakeUp                           = 0x36F6
+	cmdGetSensorVariant                 = 0x202F
+)
+
+// Command execution times
+const (
+	commandTime       = 1 * time.Millisecond
+	stopTime          = 500 * time.Millisecond
+	recalibrationTime = 400 * time.Millisecond
+	persistTime       = 800 * time.Millisecond
+	selfTestTime      = 10 * time.Second
+	factoryResetTime  = 1200 * time.Millisecond
+	reinitTime        = 20 * time.Millisecond
+	wakeUpTime        = 30 * time.Millisecond
+)
+
+// Measurement intervals
+const (
+	PeriodicInterval = 5 * time.Second
+	LowPowerInterval = 30 * time.Second
+	SingleShotTime   = 5 * time.Second // until a single shot has data
+)
+
+// Response values
+const (
+	dataReadyMask       = 0x07FF // data ready unless these bits are all 0
+	variantShift        = 12     // the variant is in the top 4 bits
+	recalibrationFailed = 0xFFFF
+	recalibrationOffset = 0x8000 // FRC correction is returned plus this
+)
+
+// Variant identifies the sensor model.
+type Variant uint8
+
+const (
+	SCD40 Variant = 0
+	SCD41 Variant = 1
+	SCD43 Variant = 5
+)
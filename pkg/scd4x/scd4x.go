@@ -0,0 +1,346 @@
+// Package scd4x provides a driver for the Sensirion SCD40/SCD41 NDIR CO2
+// sensors, which also measure temperature and relative humidity.
+//
+// Every word read from the sensor is checked against its CRC. Commands
+// marked "idle only" are rejected by the sensor while a periodic
+// measurement runs.
+//
+// Command codes and timings follow the Sensirion SCD4x datasheet.
+package scd4x
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	ErrCRC                 = errors.New("scd4x: crc mismatch")
+	ErrNotReady            = errors.New("scd4x: no new measurement")
+	ErrRecalibrationFailed = errors.New("scd4x: forced recalibration failed")
+	ErrSelfTestFailed      = errors.New("scd4x: self-test detected a malfunction")
+)
+
+// onceSender is implemented by buses that retry failed transactions, like
+// hal.RecoveringI2C, to send one transaction without retrying.
+type onceSender interface {
+	TxOnce(addr uint16, w, r []byte) error
+}
+
+// Device wraps an I2C connection to an SCD4x device.
+type Device struct {
+	bus  drivers.I2C
+	addr uint16
+
+	// Sleep waits for commands to execute; time.Sleep unless replaced,
+	// e.g. by a fake clock in tests.
+	Sleep func(time.Duration)
+
+	// last measurement
+	co2         uint16 // ppm
+	temperature int32  // milli °C
+	humidity    int32  // milli %RH
+
+	// pre-allocated buffers
+	wbuf [5]byte // longest write: command + one word with CRC
+	rbuf [9]byte // longest read: three words with CRC
+}
+
+// New returns a new SCD4x driver.
+func New(bus drivers.I2C, addr uint16) *Device {
+	if addr == 0 {
+		addr = DefaultAddress
+	}
+	return &Device{bus: bus, addr: addr, Sleep: time.Sleep}
+}
+
+// Configure stops a periodic measurement left running and reloads the
+// settings from the EEPROM.
+func (d *Device) Configure() error {
+	if err := d.StopPeriodicMeasurement(); err != nil {
+		return err
+	}
+	return d.Reinit()
+}
+
+// StartPeriodicMeasurement measures every PeriodicInterval.
+func (d *Device) StartPeriodicMeasurement() error {
+	return d.command(cmdStartPeriodicMeasurement, 0)
+}
+
+// StartLowPowerPeriodicMeasurement measures every LowPowerInterval.
+func (d *Device) StartLowPowerPeriodicMeasurement() error {
+	return d.command(cmdStartLowPowerPeriodicMeasurement, 0)
+}
+
+// StopPeriodicMeasurement returns the sensor to idle.
+func (d *Device) StopPeriodicMeasurement() error {
+	return d.command(cmdStopPeriodicMeasurement, stopTime)
+}
+
+// MeasureSingleShot starts one measurement, whose data is ready after
+// SingleShotTime (idle only, not on the SCD40).
+func (d *Device) MeasureSingleShot() error {
+	return d.command(cmdMeasureSingleShot, 0)
+}
+
+// DataReady reports whether a new measurement can be read.
+func (d *Device) DataReady() (bool, error) {
+	var status [1]uint16
+	if err := d.read(cmdGetDataReadyStatus, commandTime, status[:]); err != nil {
+		return false, err
+	}
+	return status[0]&dataReadyMask != 0, nil
+}
+
+// ReadMeasurement reads the last measurement, which the sensor then
+// discards.
+func (d *Device) ReadMeasurement() error {
+	var words [3]uint16
+	if err := d.read(cmdReadMeasurement, commandTime, words[:]); err != nil {
+		return err
+	}
+	d.co2 = words[0]
+	d.temperature = -45000 + fromTicks(words[1], 175000) // -45 + 175 * word / 2¹⁶
+	d.humidity = fromTicks(words[2], 100000)             // 100 * word / 2¹⁶
+	return nil
+}
+
+// Update reads a new measurement. It returns ErrNotReady and keeps the
+// last measurement while the sensor has none.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&(drivers.Concentration|drivers.Temperature|drivers.Humidity) == 0 {
+		return nil // nothing requested
+	}
+	ready, err := d.DataReady()
+	if err != nil {
+		return err
+	}
+	if !ready {
+		return ErrNotReady
+	}
+	return d.ReadMeasurement()
+}
+
+// CO2 returns the last CO2 concentration in ppm.
+func (d *Device) CO2() uint16 { return d.co2 }
+
+// Temperature returns the last temperature in milli-degrees Celsius.
+func (d *Device) Temperature() int32 { return d.temperature }
+
+// Humidity returns the last relative humidity in milli-percent.
+func (d *Device) Humidity() int32 { return d.humidity }
+
+// SetTemperatureOffset sets how much the sensor's own heat raises the
+// measured temperature, in milli-degrees Celsius (idle only).
+func (d *Device) SetTemperatureOffset(milliC int32) error {
+	ticks := min(max(int64(milliC), 0)<<16/175000, 0xFFFF)
+	return d.write(cmdSetTemperatureOffset, uint16(ticks), commandTime)
+}
+
+// TemperatureOffset returns the temperature offset in milli-degrees
+// Celsius (idle only).
+func (d *Device) TemperatureOffset() (int32, error) {
+	var w [1]uint16
+	if err := d.read(cmdGetTemperatureOffset, commandTime, w[:]); err != nil {
+		return 0, err
+	}
+	return fromTicks(w[0], 175000), nil
+}
+
+// SetSensorAltitude sets the altitude in metres above sea level used for
+// pressure compensation (idle only).
+func (d *Device) SetSensorAltitude(metres uint16) error {
+	return d.write(cmdSetSensorAltitude, metres, commandTime)
+}
+
+// SensorAltitude returns the altitude in metres (idle only).
+func (d *Device) SensorAltitude() (uint16, error) {
+	var w [1]uint16
+	err := d.read(cmdGetSensorAltitude, commandTime, w[:])
+	return w[0], err
+}
+
+// SetAmbientPressure sets the ambient pressure in hPa, overriding the
+// altitude. It is also accepted while measuring.
+func (d *Device) SetAmbientPressure(hPa uint16) error {
+	return d.write(cmdSetAmbientPressure, hPa, commandTime)
+}
+
+// ForcedRecalibration corrects the CO2 reading to reference ppm and returns
+// the correction applied (idle only). The sensor must have measured air of
+// that concentration for at least three minutes before.
+func (d *Device) ForcedRecalibration(reference uint16) (int16, error) {
+	if err := d.write(cmdPerformForcedRecalibration, reference, recalibrationTime); err != nil {
+		return 0, err
+	}
+	var w [1]uint16
+	if err := d.readWords(w[:]); err != nil {
+		return 0, err
+	}
+	if w[0] == recalibrationFailed {
+		return 0, ErrRecalibrationFailed
+	}
+	return int16(int32(w[0]) - recalibrationOffset), nil
+}
+
+// SetAutomaticSelfCalibration turns automatic self-calibration on or off
+// (idle only).
+func (d *Device) SetAutomaticSelfCalibration(on bool) error {
+	var v uint16
+	if on {
+		v = 1
+	}
+	return d.write(cmdSetAutomaticSelfCalibration, v, commandTime)
+}
+
+// AutomaticSelfCalibration reports whether automatic self-calibration is
+// on (idle only).
+func (d *Device) AutomaticSelfCalibration() (bool, error) {
+	var w [1]uint16
+	err := d.read(cmdGetAutomaticSelfCalibration, commandTime, w[:])
+	return w[0] != 0, err
+}
+
+// PersistSettings stores the temperature offset, altitude and
+// self-calibration setting in the EEPROM (idle only). The EEPROM is rated
+// for 2000 writes, so only persist settings that changed.
+func (d *Device) PersistSettings() error {
+	return d.command(cmdPersistSettings, persistTime)
+}
+
+// SerialNumber returns the 48-bit serial number (idle only).
+func (d *Device) SerialNumber() (uint64, error) {
+	var w [3]uint16
+	if err := d.read(cmdGetSerialNumber, commandTime, w[:]); err != nil {
+		return 0, err
+	}
+	return uint64(w[0])<<32 | uint64(w[1])<<16 | uint64(w[2]), nil
+}
+
+// SensorVariant returns the sensor model (idle only). Sensors with older
+// firmware do not know the command.
+func (d *Device) SensorVariant() (Variant, error) {
+	var w [1]uint16
+	if err := d.read(cmdGetSensorVariant, commandTime, w[:]); err != nil {
+		return 0, err
+	}
+	return Variant(w[0] >> variantShift), nil
+}
+
+// SelfTest checks the sensor, which takes 10 s (idle only).
+func (d *Device) SelfTest() error {
+	var w [1]uint16
+	if err := d.read(cmdPerformSelfTest, selfTestTime, w[:]); err != nil {
+		return err
+	}
+	if w[0] != 0 {
+		return ErrSelfTestFailed
+	}
+	return nil
+}
+
+// FactoryReset restores the factory settings in the EEPROM and erases the
+// calibration history (idle only).
+func (d *Device) FactoryReset() error {
+	return d.command(cmdPerformFactoryReset, factoryResetTime)
+}
+
+// Reinit reloads the settings from the EEPROM (idle only).
+func (d *Device) Reinit() error {
+	return d.command(cmdReinit, reinitTime)
+}
+
+// PowerDown turns the sensor off until WakeUp (idle only, SCD41 only).
+func (d *Device) PowerDown() error {
+	return d.command(cmdPowerDown, commandTime)
+}
+
+// WakeUp powers the sensor up again and checks that it answers by reading
+// the serial number. The sensor does not acknowledge the wake-up command
+// itself, so it is sent without retries where the bus retries and its
+// error is ignored. The first single shot after waking up should be
+// discarded.
+func (d *Device) WakeUp() error {
+	tx := d.bus.Tx
+	if b, ok := d.bus.(onceSender); ok {
+		tx = b.TxOnce
+	}
+	binary.BigEndian.PutUint16(d.wbuf[0:], cmdWakeUp)
+	tx(d.addr, d.wbuf[:2], nil) // never acknowledged
+	d.wait(wakeUpTime)
+
+	_, err := d.SerialNumber()
+	return err
+}
+
+// command sends cmd and waits for it to execute.
+func (d *Device) command(cmd uint16, wait time.Duration) error {
+	binary.BigEndian.PutUint16(d.wbuf[0:], cmd)
+	err := d.bus.Tx(d.addr, d.wbuf[:2], nil)
+	d.wait(wait)
+	return err
+}
+
+// write sends cmd with one argument word and waits for it to execute.
+func (d *Device) write(cmd, value uint16, wait time.Duration) error {
+	binary.BigEndian.PutUint16(d.wbuf[0:], cmd)
+	binary.BigEndian.PutUint16(d.wbuf[2:], value)
+	d.wbuf[4] = crc8(d.wbuf[2:4])
+	err := d.bus.Tx(d.addr, d.wbuf[:5], nil)
+	d.wait(wait)
+	return err
+}
+
+// read sends cmd, waits for it to execute and reads len(words) words.
+func (d *Device) read(cmd uint16, wait time.Duration, words []uint16) error {
+	if err := d.command(cmd, wait); err != nil {
+		return err
+	}
+	return d.readWords(words)
+}
+
+// readWords reads words, each followed by its CRC.
+func (d *Device) readWords(words []uint16) error {
+	buf := d.rbuf[:3*len(words)]
+	if err := d.bus.Tx(d.addr, nil, buf); err != nil {
+		return err
+	}
+	for i := range words {
+		b := buf[3*i:]
+		if crc8(b[0:2]) != b[2] {
+			return ErrCRC
+		}
+		words[i] = binary.BigEndian.Uint16(b[0:2])
+	}
+	return nil
+}
+
+func (d *Device) wait(t time.Duration) {
+	if t > 0 {
+		d.Sleep(t)
+	}
+}
+
+// fromTicks scales a word to full / 2¹⁶.
+func fromTicks(word uint16, full int64) int32 {
+	return int32(full * int64(word) >> 16)
+}
+
+// crc8 is the Sensirion CRC-8 (polynomial 0x31, init 0xFF).
+func crc8(buf []byte) uint8 {
+	crc := uint8(0xFF)
+	for _, b := range buf {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
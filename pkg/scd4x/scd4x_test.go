@@ -0,0 +1,218 @@
+package scd4x
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeBus answers every read with the words set for the last command and
+// records the commands and their arguments.
+type fakeBus struct {
+	replies map[uint16][]uint16
+	badCRC  bool
+	sent    []uint16 // commands, each followed by its argument if any
+	last    uint16
+	slept   time.Duration
+}
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	if addr != DefaultAddress {
+		return errors.New("nack")
+	}
+	if len(w) >= 2 {
+		b.last = binary.BigEndian.Uint16(w)
+		b.sent = append(b.sent, b.last)
+	}
+	if len(w) == 5 {
+		if crc8(w[2:4]) != w[4] {
+			return errors.New("bad crc written")
+		}
+		b.sent = append(b.sent, binary.BigEndian.Uint16(w[2:]))
+	}
+	for i, word := range b.replies[b.last] {
+		if 3*i+3 > len(r) {
+			break
+		}
+		binary.BigEndian.PutUint16(r[3*i:], word)
+		r[3*i+2] = crc8(r[3*i : 3*i+2])
+		if b.badCRC {
+			r[3*i+2]++
+		}
+	}
+	return nil
+}
+
+func newTestDevice(replies map[uint16][]uint16) (*Device, *fakeBus) {
+	bus := &fakeBus{replies: replies}
+	d := New(bus, 0)
+	d.Sleep = func(t time.Duration) { bus.slept += t }
+	return d, bus
+}
+
+func TestCRC(t *testing.T) {
+	// Example from the datasheet.
+	if got := crc8([]byte{0xBE, 0xEF}); got != 0x92 {
+		t.Errorf("crc8(BEEF) = %#x, want 0x92", got)
+	}
+}
+
+func TestUpdateWaitsForData(t *testing.T) {
+	d, bus := newTestDevice(map[uint16][]uint16{
+		cmdGetDataReadyStatus: {0x8000},
+		cmdReadMeasurement:    {800, 0x6666, 0x8000},
+	})
+
+	if err := d.Update(0xFF); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("Expected ErrNotReady, got %v", err)
+	}
+
+	bus.replies[cmdGetDataReadyStatus] = []uint16{0x8006}
+	if err := d.Update(0xFF); err != nil {
+		t.Fatal(err)
+	}
+	if d.CO2() != 800 || d.Temperature() != 24998 || d.Humidity() != 50000 {
+		t.Errorf("Expected 800 ppm, 24.998 °C, 50 %%RH, got %d, %d, %d",
+			d.CO2(), d.Temperature(), d.Humidity())
+	}
+
+	bus.badCRC = true
+	if err := d.Update(0xFF); !errors.Is(err, ErrCRC) {
+		t.Errorf("Expected ErrCRC, got %v", err)
+	}
+	if d.CO2() != 800 {
+		t.Errorf("Expected the last measurement to be kept, got %d", d.CO2())
+	}
+}
+
+func TestSettings(t *testing.T) {
+	d, bus := newTestDevice(map[uint16][]uint16{
+		cmdGetTemperatureOffset:        {0x05DA}, // 4.0 °C
+		cmdGetSensorAltitude:           {520},
+		cmdGetAutomaticSelfCalibration: {0},
+		cmdGetSerialNumber:             {0x1234, 0x5678, 0x9ABC},
+		cmdGetSensorVariant:            {0x1000},
+	})
+
+	steps := []error{
+		d.SetTemperatureOffset(4000),
+		d.SetSensorAltitude(520),
+		d.SetAmbientPressure(987),
+		d.SetAutomaticSelfCalibration(false),
+		d.PersistSettings(),
+	}
+	if err := errors.Join(steps...); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{
+		cmdSetTemperatureOffset, 0x05D9, // rounded down
+		cmdSetSensorAltitude, 520,
+		cmdSetAmbientPressure, 987,
+		cmdSetAutomaticSelfCalibration, 0,
+		cmdPersistSettings,
+	}
+	if !slices.Equal(bus.sent, want) {
+		t.Errorf("Expected %04X, got %04X", want, bus.sent)
+	}
+	if bus.slept != 4*commandTime+persistTime {
+		t.Errorf("Expected to wait %s, waited %s", 4*commandTime+persistTime, bus.slept)
+	}
+
+	if off, err := d.TemperatureOffset(); err != nil || off != 4000 {
+		t.Errorf("Expected a 4000 m°C offset, got %d, %v", off, err)
+	}
+	if alt, err := d.SensorAltitude(); err != nil || alt != 520 {
+		t.Errorf("Expected 520 m, got %d, %v", alt, err)
+	}
+	if asc, err := d.AutomaticSelfCalibration(); err != nil || asc {
+		t.Errorf("Expected self-calibration off, got %v, %v", asc, err)
+	}
+	if sn, err := d.SerialNumber(); err != nil || sn != 0x123456789ABC {
+		t.Errorf("Expected serial 123456789ABC, got %X, %v", sn, err)
+	}
+	if v, err := d.SensorVariant(); err != nil || v != SCD41 {
+		t.Errorf("Expected an SCD41, got %d, %v", v, err)
+	}
+}
+
+func TestForcedRecalibration(t *testing.T) {
+	d, bus := newTestDevice(map[uint16][]uint16{
+		cmdPerformForcedRecalibration: {0x8000 - 25},
+	})
+
+	corr, err := d.ForcedRecalibration(420)
+	if err != nil || corr != -25 {
+		t.Errorf("Expected a -25 ppm correction, got %d, %v", corr, err)
+	}
+	if bus.slept != recalibrationTime {
+		t.Errorf("Expected to wait %s, waited %s", recalibrationTime, bus.slept)
+	}
+
+	bus.replies[cmdPerformForcedRecalibration] = []uint16{0xFFFF}
+	if _, err := d.ForcedRecalibration(420); !errors.Is(err, ErrRecalibrationFailed) {
+		t.Errorf("Expected ErrRecalibrationFailed, got %v", err)
+	}
+}
+
+func TestSelfTestAndReset(t *testing.T) {
+	d, bus := newTestDevice(map[uint16][]uint16{
+		cmdPerformSelfTest: {0},
+	})
+
+	if err := d.SelfTest(); err != nil {
+		t.Errorf("Expected the self-test to pass, got %v", err)
+	}
+	bus.replies[cmdPerformSelfTest] = []uint16{0x0001}
+	if err := d.SelfTest(); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("Expected ErrSelfTestFailed, got %v", err)
+	}
+
+	bus.sent, bus.slept = nil, 0
+	if err := errors.Join(d.Configure(), d.FactoryReset()); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{cmdStopPeriodicMeasurement, cmdReinit, cmdPerformFactoryReset}
+	if !slices.Equal(bus.sent, want) {
+		t.Errorf("Expected %04X, got %04X", want, bus.sent)
+	}
+	if want := stopTime + reinitTime + factoryResetTime; bus.slept != want {
+		t.Errorf("Expected to wait %s, waited %s", want, bus.slept)
+	}
+}
+
+// onceBus is a fakeBus that NACKs every transaction sent with TxOnce.
+type onceBus struct {
+	*fakeBus
+	once int
+}
+
+func (b *onceBus) TxOnce(addr uint16, w, r []byte) error {
+	b.once++
+	b.sent = append(b.sent, binary.BigEndian.Uint16(w))
+	return errors.New("nack")
+}
+
+func TestWakeUp(t *testing.T) {
+	fake := &fakeBus{replies: map[uint16][]uint16{
+		cmdGetSerialNumber: {0x1234, 0x5678, 0x9ABC},
+	}}
+	bus := &onceBus{fakeBus: fake}
+	d := New(bus, 0)
+	d.Sleep = func(t time.Duration) { fake.slept += t }
+
+	if err := d.WakeUp(); err != nil {
+		t.Fatalf("Expected the unacknowledged wake-up to be ignored, got %v", err)
+	}
+	if bus.once != 1 {
+		t.Errorf("Expected the wake-up sent once without retries, got %d", bus.once)
+	}
+	want := []uint16{cmdWakeUp, cmdGetSerialNumber}
+	if !slices.Equal(fake.sent, want) {
+		t.Errorf("Expected %04X, got %04X", want, fake.sent)
+	}
+	if want := wakeUpTime + commandTime; fake.slept != want {
+		t.Errorf("Expected to wait %s, waited %s", want, fake.slept)
+	}
+}
@@ -0,0 +1,215 @@
+// Package scheduler provides a cooperative, single-threaded task scheduler.
+//
+// Tasks are either periodic, run when a condition holds, or run on demand,
+// optionally at a deadline. The scheduler runs every due task and then
+// sleeps until the nearest deadline, so the main loop only wakes up when
+// there is work to do.
+package scheduler
+
+import "time"
+
+// Clock provides the current time and blocking sleeps.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// Task is a unit of work registered with a Scheduler.
+type Task struct {
+	Name     string
+	fn       func()
+	period   time.Duration
+	when     func() bool
+	next     time.Time
+	pending  bool
+	Runs     int
+	Overruns int           // deadlines missed by at least one full period
+	LastRun  time.Duration // duration of the last run
+}
+
+// SetPeriod changes the period of a periodic task. The next deadline is
+// moved closer when the new period is shorter.
+func (t *Task) SetPeriod(d time.Duration) {
+	if d == t.period {
+		return
+	}
+	if d < t.period && !t.next.IsZero() {
+		t.next = t.next.Add(d - t.period)
+	}
+	t.period = d
+}
+
+// Period returns the current period of the task.
+func (t *Task) Period() time.Duration {
+	return t.period
+}
+
+// Next returns the next deadline of a periodic task, or of an on-demand
+// task scheduled with TriggerAt. It is zero before the first run of a
+// periodic task and when an on-demand task is not scheduled.
+func (t *Task) Next() time.Time {
+	return t.next
+}
+
+// Trigger makes the task run on the next pass of the scheduler.
+func (t *Task) Trigger() {
+	t.pending = true
+}
+
+// TriggerAt makes an on-demand task run at the given time. An earlier
+// deadline that is already set is kept; a zero time is ignored.
+func (t *Task) TriggerAt(at time.Time) {
+	if at.IsZero() || t.period > 0 || t.when != nil {
+		return
+	}
+	if t.next.IsZero() || at.Before(t.next) {
+		t.next = at
+	}
+}
+
+// Scheduler runs registered tasks in registration order.
+type Scheduler struct {
+	clock Clock
+	tasks []*Task
+
+	// MaxSleep bounds the time Run sleeps between passes, so on-demand and
+	// conditional tasks are still served without periodic ones.
+	MaxSleep time.Duration
+
+	// OnOverrun is called when a periodic task starts late by at least one
+	// full period, or when a run takes longer than the period.
+	OnOverrun func(t *Task, late time.Duration)
+}
+
+// New returns an empty scheduler.
+func New(clock Clock) *Scheduler {
+	return &Scheduler{
+		clock:    clock,
+		MaxSleep: time.Second,
+	}
+}
+
+// Every registers fn to run every period, starting immediately.
+func (s *Scheduler) Every(name string, period time.Duration, fn func()) *Task {
+	return s.add(&Task{Name: name, fn: fn, period: period})
+}
+
+// When registers fn to run on every pass in which cond returns true.
+// Conditions are evaluated after all tasks registered before it ran. They
+// never shorten the sleep between passes: a condition that becomes true
+// without another task running is noticed after MaxSleep at the latest.
+func (s *Scheduler) When(name string, cond func() bool, fn func()) *Task {
+	return s.add(&Task{Name: name, fn: fn, when: cond})
+}
+
+// OnDemand registers fn to run only after Task.Trigger was called, or at
+// the time given to Task.TriggerAt.
+func (s *Scheduler) OnDemand(name string, fn func()) *Task {
+	return s.add(&Task{Name: name, fn: fn})
+}
+
+// Tasks returns the registered tasks.
+func (s *Scheduler) Tasks() []*Task {
+	return s.tasks
+}
+
+func (s *Scheduler) add(t *Task) *Task {
+	s.tasks = append(s.tasks, t)
+	return t
+}
+
+// RunPending runs every task that is due and returns the time until the
+// next periodic deadline, capped at MaxSleep.
+func (s *Scheduler) RunPending() time.Duration {
+	for _, t := range s.tasks {
+		now := s.clock.Now()
+		if !s.due(t, now) {
+			continue
+		}
+		s.run(t, now)
+	}
+
+	return s.untilNext()
+}
+
+// Run executes tasks forever, sleeping between deadlines.
+func (s *Scheduler) Run() {
+	for {
+		if wait := s.RunPending(); wait > 0 {
+			s.clock.Sleep(wait)
+		}
+	}
+}
+
+func (s *Scheduler) due(t *Task, now time.Time) bool {
+	switch {
+	case t.pending:
+		return true
+	case t.when != nil:
+		return t.when()
+	case t.period > 0:
+		return !now.Before(t.next)
+	default:
+		return !t.next.IsZero() && !now.Before(t.next)
+	}
+}
+
+func (s *Scheduler) run(t *Task, now time.Time) {
+	t.pending = false
+	if t.period <= 0 {
+		t.next = time.Time{} // fn may schedule the next run
+	}
+
+	if t.period > 0 && t.when == nil {
+		if !t.next.IsZero() {
+			if late := now.Sub(t.next); late >= t.period {
+				s.overrun(t, late)
+			}
+		}
+	}
+
+	t.fn()
+	end := s.clock.Now()
+	t.LastRun = end.Sub(now)
+	t.Runs++
+
+	if t.period > 0 {
+		if t.LastRun > t.period {
+			s.overrun(t, t.LastRun-t.period)
+		}
+		// Keep the cadence unless the task fell behind, then skip the
+		// missed slots instead of running them back to back.
+		next := t.next.Add(t.period)
+		if t.next.IsZero() || next.Before(end) {
+			next = now.Add(t.period)
+		}
+		t.next = next
+	}
+}
+
+func (s *Scheduler) overrun(t *Task, late time.Duration) {
+	t.Overruns++
+	if s.OnOverrun != nil {
+		s.OnOverrun(t, late)
+	}
+}
+
+func (s *Scheduler) untilNext() time.Duration {
+	now := s.clock.Now()
+	wait := s.MaxSleep
+	for _, t := range s.tasks {
+		if t.pending {
+			return 0
+		}
+		if t.when != nil || (t.period <= 0 && t.next.IsZero()) {
+			continue
+		}
+		if d := t.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestSchedulerPeriodicTasks(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := New(clock)
+
+	var fast, slow int
+	s.Every("fast", 100*time.Millisecond, func() { fast++ })
+	s.Every("slow", time.Second, func() { slow++ })
+
+	wakeups := 0
+	for clock.now.Before(time.Date(2026, 1, 1, 0, 0, 2, 0, time.UTC)) {
+		wait := s.RunPending()
+		clock.Sleep(wait)
+		wakeups++
+	}
+
+	if fast != 20 || slow != 2 {
+		t.Errorf("Expected 20 fast and 2 slow runs, got %d and %d", fast, slow)
+	}
+	if wakeups != 20 {
+		t.Errorf("Expected to wake up only on deadlines (20), got %d", wakeups)
+	}
+}
+
+func TestSchedulerConditionalAndOnDemand(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := New(clock)
+
+	dirty := false
+	renders, saves := 0, 0
+	s.Every("update", time.Second, func() { dirty = true })
+	s.When("render", func() bool { return dirty }, func() {
+		renders++
+		dirty = false
+	})
+	save := s.OnDemand("save", func() { saves++ })
+
+	s.RunPending()
+	s.RunPending()
+	if renders != 1 {
+		t.Errorf("Expected one render after update, got %d", renders)
+	}
+	if saves != 0 {
+		t.Errorf("Expected no save before trigger, got %d", saves)
+	}
+
+	save.Trigger()
+	if wait := s.RunPending(); wait != time.Second {
+		t.Errorf("Expected to sleep until the next update, got %v", wait)
+	}
+	if saves != 1 {
+		t.Errorf("Expected one save after trigger, got %d", saves)
+	}
+}
+
+func TestSchedulerReportsOverruns(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := New(clock)
+
+	var reported []string
+	s.OnOverrun = func(task *Task, late time.Duration) {
+		reported = append(reported, task.Name)
+	}
+
+	s.Every("slow-task", 100*time.Millisecond, func() {
+		clock.Sleep(250 * time.Millisecond)
+	})
+	tick := s.Every("tick", 100*time.Millisecond, func() {})
+
+	s.RunPending()
+	s.RunPending()
+
+	if len(reported) == 0 || reported[0] != "slow-task" {
+		t.Fatalf("Expected slow-task overrun, got %v", reported)
+	}
+	if tick.Overruns == 0 {
+		t.Errorf("Expected tick to be reported late")
+	}
+}
+
+func TestSchedulerSetPeriod(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := New(clock)
+	s.MaxSleep = time.Hour
+
+	task := s.Every("sensors", time.Second, func() {})
+	s.RunPending()
+	task.SetPeriod(time.Minute)
+
+	clock.Sleep(time.Second)
+	if wait := s.RunPending(); wait != time.Minute {
+		t.Errorf("Expected next run in a minute, got %v", wait)
+	}
+}
+
+func TestSchedulerTriggerAt(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	s := New(clock)
+	s.MaxSleep = time.Minute
+
+	var blink *Task
+	runs := 0
+	blink = s.OnDemand("blink", func() {
+		runs++
+		if runs < 3 {
+			blink.TriggerAt(clock.now.Add(200 * time.Millisecond))
+		}
+	})
+	s.When("never", func() bool { return false }, func() {})
+
+	if wait := s.RunPending(); wait != time.Minute {
+		t.Errorf("Expected to sleep MaxSleep without deadlines, got %v", wait)
+	}
+
+	blink.TriggerAt(start.Add(time.Second))
+	blink.TriggerAt(start.Add(2 * time.Second))
+	wait := s.RunPending()
+	for runs < 3 {
+		clock.Sleep(wait)
+		wait = s.RunPending()
+	}
+	if got := clock.now.Sub(start); got != 1400*time.Millisecond {
+		t.Errorf("Expected the last run after 1.4s, got %v", got)
+	}
+	if wait != time.Minute || !blink.Next().IsZero() {
+		t.Errorf("Expected no deadline after the last run, got %v", wait)
+	}
+}
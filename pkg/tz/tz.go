@@ -0,0 +1,322 @@
+// Package tz converts UTC to local time using a fixed offset or a POSIX TZ
+// rule such as "CET-1CEST,M3.5.0,M10.5.0/3".
+//
+// TinyGo has no time zone database, so the rule is evaluated directly
+// instead of going through time.LoadLocation.
+package tz
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrSyntax = errors.New("tz: invalid zone")
+
+// Zone is a time zone with an optional yearly daylight saving period.
+type Zone struct {
+	spec    string
+	stdName string
+	stdOff  int // seconds east of UTC
+	dstName string
+	dstOff  int
+	hasDST  bool
+	start   rule // in standard time
+	end     rule // in daylight saving time
+}
+
+type ruleKind uint8
+
+const (
+	julianNoLeap ruleKind = iota // Jn, 1-365, February 29 is never counted
+	julian                       // n, 0-365
+	monthWeekDay                 // Mm.w.d
+)
+
+type rule struct {
+	kind  ruleKind
+	day   int // Jn/n day, or weekday for Mm.w.d
+	week  int // 1-5, 5 is the last week
+	month int
+	time  int // seconds after local midnight
+}
+
+// UTC is the zone without offset.
+var UTC = &Zone{spec: "UTC0", stdName: "UTC"}
+
+// Parse parses a zone. Besides POSIX TZ strings it accepts "UTC", an empty
+// string (UTC) and ISO-style fixed offsets such as "+02:00" or "-0330".
+func Parse(s string) (*Zone, error) {
+	if s == "" || s == "UTC" || s == "Z" {
+		return UTC, nil
+	}
+	if s[0] == '+' || s[0] == '-' {
+		return parseFixed(s)
+	}
+
+	p := parser{s: s}
+	z := &Zone{spec: s}
+	var ok bool
+
+	if z.stdName, ok = p.name(); !ok {
+		return nil, ErrSyntax
+	}
+	off, ok := p.offset()
+	if !ok {
+		return nil, ErrSyntax
+	}
+	z.stdOff = -off // POSIX offsets are positive west of Greenwich
+	if p.done() {
+		return z, nil
+	}
+
+	if z.dstName, ok = p.name(); !ok {
+		return nil, ErrSyntax
+	}
+	z.hasDST = true
+	z.dstOff = z.stdOff + 3600
+	if !p.done() && p.peek() != ',' {
+		if off, ok = p.offset(); !ok {
+			return nil, ErrSyntax
+		}
+		z.dstOff = -off
+	}
+
+	if p.done() {
+		// No rules given: use the US rules like the C library does.
+		z.start = rule{kind: monthWeekDay, month: 3, week: 2, time: 7200}
+		z.end = rule{kind: monthWeekDay, month: 11, week: 1, time: 7200}
+		return z, nil
+	}
+
+	if !p.consume(',') {
+		return nil, ErrSyntax
+	}
+	if z.start, ok = p.rule(); !ok || !p.consume(',') {
+		return nil, ErrSyntax
+	}
+	if z.end, ok = p.rule(); !ok || !p.done() {
+		return nil, ErrSyntax
+	}
+	return z, nil
+}
+
+func parseFixed(s string) (*Zone, error) {
+	digits := strings.ReplaceAll(s[1:], ":", "")
+	if len(digits) != 2 && len(digits) != 4 {
+		return nil, ErrSyntax
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil, ErrSyntax
+	}
+	if len(digits) == 2 {
+		n *= 100
+	}
+	hours, minutes := n/100, n%100
+	if hours > 14 || minutes > 59 {
+		return nil, ErrSyntax
+	}
+
+	off := hours*3600 + minutes*60
+	if s[0] == '-' {
+		off = -off
+	}
+	return &Zone{spec: s, stdName: s, stdOff: off}, nil
+}
+
+// String returns the zone as it was parsed.
+func (z *Zone) String() string {
+	return z.spec
+}
+
+// Lookup returns the abbreviation and offset in seconds east of UTC in
+// effect at t.
+func (z *Zone) Lookup(t time.Time) (name string, offset int) {
+	if z.isDST(t) {
+		return z.dstName, z.dstOff
+	}
+	return z.stdName, z.stdOff
+}
+
+// In returns t in the local time of z.
+func (z *Zone) In(t time.Time) time.Time {
+	name, off := z.Lookup(t)
+	return t.In(time.FixedZone(name, off))
+}
+
+// Date returns the instant of a local wall clock time in z. Times skipped
+// at the start of daylight saving are moved forward, times repeated at its
+// end resolve to the daylight saving instant.
+func (z *Zone) Date(year int, month time.Month, day, hour, min, sec int) time.Time {
+	wall := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	if t := wall.Add(-time.Duration(z.dstOff) * time.Second); z.isDST(t) {
+		return t
+	}
+	return wall.Add(-time.Duration(z.stdOff) * time.Second)
+}
+
+func (z *Zone) isDST(t time.Time) bool {
+	if !z.hasDST {
+		return false
+	}
+
+	year := t.UTC().Year()
+	start := z.start.at(year) - int64(z.stdOff)
+	end := z.end.at(year) - int64(z.dstOff)
+	u := t.Unix()
+
+	if start < end {
+		return u >= start && u < end
+	}
+	// Southern hemisphere: daylight saving spans the new year.
+	return u >= start || u < end
+}
+
+// at returns the transition in the given year as seconds since the epoch in
+// local time.
+func (r rule) at(year int) int64 {
+	var date time.Time
+	switch r.kind {
+	case julianNoLeap:
+		date = time.Date(year, time.January, r.day, 0, 0, 0, 0, time.UTC)
+		if isLeap(year) && r.day >= 60 {
+			date = date.AddDate(0, 0, 1)
+		}
+	case julian:
+		date = time.Date(year, time.January, 1+r.day, 0, 0, 0, 0, time.UTC)
+	case monthWeekDay:
+		first := time.Date(year, time.Month(r.month), 1, 0, 0, 0, 0, time.UTC)
+		day := 1 + (r.day-int(first.Weekday())+7)%7 + (r.week-1)*7
+		if last := daysIn(year, r.month); day > last {
+			day -= 7
+		}
+		date = time.Date(year, time.Month(r.month), day, 0, 0, 0, 0, time.UTC)
+	}
+	return date.Unix() + int64(r.time)
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.s) }
+
+func (p *parser) peek() byte { return p.s[p.pos] }
+
+func (p *parser) consume(c byte) bool {
+	if p.done() || p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// name parses an abbreviation: three or more letters, or any text in <>.
+func (p *parser) name() (string, bool) {
+	if p.consume('<') {
+		end := strings.IndexByte(p.s[p.pos:], '>')
+		if end < 1 {
+			return "", false
+		}
+		name := p.s[p.pos : p.pos+end]
+		p.pos += end + 1
+		return name, true
+	}
+
+	start := p.pos
+	for !p.done() && isAlpha(p.peek()) {
+		p.pos++
+	}
+	if p.pos-start < 3 {
+		return "", false
+	}
+	return p.s[start:p.pos], true
+}
+
+// offset parses [+-]hh[:mm[:ss]] into seconds.
+func (p *parser) offset() (int, bool) {
+	sign := 1
+	if p.consume('-') {
+		sign = -1
+	} else {
+		p.consume('+')
+	}
+
+	secs := 0
+	for i, unit := range [...]int{3600, 60, 1} {
+		if i > 0 && !p.consume(':') {
+			break
+		}
+		n, ok := p.number()
+		if !ok || (i == 0 && n > 167) || (i > 0 && n > 59) {
+			return 0, false
+		}
+		secs += n * unit
+	}
+	return sign * secs, true
+}
+
+// rule parses Jn, n or Mm.w.d with an optional /time.
+func (p *parser) rule() (rule, bool) {
+	var r rule
+	var ok bool
+
+	switch {
+	case p.consume('J'):
+		r.kind = julianNoLeap
+		if r.day, ok = p.number(); !ok || r.day < 1 || r.day > 365 {
+			return r, false
+		}
+	case p.consume('M'):
+		r.kind = monthWeekDay
+		if r.month, ok = p.number(); !ok || r.month < 1 || r.month > 12 || !p.consume('.') {
+			return r, false
+		}
+		if r.week, ok = p.number(); !ok || r.week < 1 || r.week > 5 || !p.consume('.') {
+			return r, false
+		}
+		if r.day, ok = p.number(); !ok || r.day > 6 {
+			return r, false
+		}
+	default:
+		r.kind = julian
+		if r.day, ok = p.number(); !ok || r.day > 365 {
+			return r, false
+		}
+	}
+
+	r.time = 7200
+	if p.consume('/') {
+		if r.time, ok = p.offset(); !ok {
+			return r, false
+		}
+	}
+	return r, true
+}
+
+func (p *parser) number() (int, bool) {
+	start := p.pos
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false
+	}
+	n, err := strconv.Atoi(p.s[start:p.pos])
+	return n, err == nil
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
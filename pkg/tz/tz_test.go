@@ -0,0 +1,81 @@
+package tz
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFixedOffsets(t *testing.T) {
+	tests := []struct {
+		spec   string
+		offset int
+	}{
+		{"", 0},
+		{"UTC", 0},
+		{"+02:00", 2 * 3600},
+		{"-0330", -(3*3600 + 30*60)},
+		{"JST-9", 9 * 3600},
+		{"<+0545>-5:45", 5*3600 + 45*60},
+	}
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		z, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if _, off := z.Lookup(now); off != tt.offset {
+			t.Errorf("Expected %q offset %d, got %d", tt.spec, tt.offset, off)
+		}
+	}
+
+	for _, spec := range []string{"+25", "X-1", "CET-1CEST,M3.5.0", "CET-1CEST,M13.1.0,M10.5.0"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestDSTTransitions(t *testing.T) {
+	tests := []struct {
+		spec string
+		utc  time.Time
+		want string
+	}{
+		// Europe switches at 01:00 UTC on the last Sundays of March and October.
+		{"CET-1CEST,M3.5.0,M10.5.0/3", time.Date(2026, 3, 29, 0, 59, 0, 0, time.UTC), "01:59 CET"},
+		{"CET-1CEST,M3.5.0,M10.5.0/3", time.Date(2026, 3, 29, 1, 0, 0, 0, time.UTC), "03:00 CEST"},
+		{"CET-1CEST,M3.5.0,M10.5.0/3", time.Date(2026, 10, 25, 0, 59, 0, 0, time.UTC), "02:59 CEST"},
+		{"CET-1CEST,M3.5.0,M10.5.0/3", time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC), "02:00 CET"},
+		// US default rules when none are given.
+		{"EST5EDT", time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC), "03:00 EDT"},
+		{"EST5EDT", time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), "01:00 EST"},
+		// Southern hemisphere, daylight saving across the new year.
+		{"AEST-10AEDT,M10.1.0,M4.1.0/3", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), "11:00 AEDT"},
+		{"AEST-10AEDT,M10.1.0,M4.1.0/3", time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), "10:00 AEST"},
+	}
+	for _, tt := range tests {
+		z, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := z.In(tt.utc).Format("15:04 MST"); got != tt.want {
+			t.Errorf("Expected %s at %v in %s, got %s", tt.want, tt.utc, tt.spec, got)
+		}
+	}
+}
+
+func TestDateFromWallClock(t *testing.T) {
+	z, _ := Parse("CET-1CEST,M3.5.0,M10.5.0/3")
+
+	got := z.Date(2026, time.July, 1, 12, 0, 0)
+	if want := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// 02:30 does not exist on the spring transition day.
+	got = z.Date(2026, time.March, 29, 2, 30, 0)
+	if local := z.In(got).Format("15:04"); local != "03:30" {
+		t.Errorf("Expected skipped time to move to 03:30, got %s", local)
+	}
+}